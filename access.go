@@ -0,0 +1,63 @@
+package ptfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// AccessMode is a set of permissions checked by Access.
+type AccessMode uint32
+
+// Access modes, with the values of the X_OK, W_OK and R_OK arguments of
+// access(2).
+const (
+	AccessExecute AccessMode = 1 << iota
+	AccessWrite
+	AccessRead
+)
+
+// WithAccessIdentity makes Access check permissions for the user uid in
+// group gid. Files owned by uid are checked against their owner permission
+// bits, files in group gid against their group bits, and others against
+// their other bits. Files whose owner the underlying filesystem does not
+// report are checked against their owner bits.
+func WithAccessIdentity(uid, gid int) Option {
+	return func(f *FileSystem) error {
+		f.identity = &identity{uid, gid}
+		return nil
+	}
+}
+
+type identity struct {
+	uid, gid int
+}
+
+// Access checks whether the named file may be accessed with mode, as the
+// access(2) system call does, by comparing mode with the file's permission
+// bits. It returns nil if access is permitted, a *os.PathError wrapping
+// syscall.EACCES if it is not, and the error from Stat, which satisfies
+// errors.Is(err, os.ErrNotExist), if the file does not exist.
+//
+// The FileSystem has no notion of the calling user, so by default the file's
+// owner permission bits are checked. Use WithAccessIdentity to check the
+// group or other bits for files not owned by a given user.
+func (f *FileSystem) Access(name string, mode AccessMode) error {
+	info, err := f.Stat(name)
+	if err != nil {
+		return err
+	}
+	perm := AccessMode(info.Mode().Perm())
+	shift := 6
+	if f.identity != nil {
+		if uid, gid, ok := owner(info); ok && uid != f.identity.uid {
+			shift = 0
+			if gid == f.identity.gid {
+				shift = 3
+			}
+		}
+	}
+	if mode&^(perm>>shift&7) != 0 {
+		return &os.PathError{Op: "access", Path: name, Err: syscall.EACCES}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestAccess(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/ro", "data")
+	if err := fs.Chmod("/ro", 0444); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/script", "data")
+	if err := fs.Chmod("/script", 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Access("/ro", ptfs.AccessWrite); !errors.Is(err, syscall.EACCES) {
+		t.Fatalf("Access(/ro, write) = %v, want %v", err, syscall.EACCES)
+	}
+	if err := fs.Access("/ro", ptfs.AccessRead); err != nil {
+		t.Fatalf("Access(/ro, read) = %v", err)
+	}
+	if err := fs.Access("/script", ptfs.AccessRead|ptfs.AccessWrite|ptfs.AccessExecute); err != nil {
+		t.Fatalf("Access(/script, rwx) = %v", err)
+	}
+	if err := fs.Access("/missing", ptfs.AccessRead); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Access(/missing) = %v, want not exist", err)
+	}
+}
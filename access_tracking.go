@@ -0,0 +1,115 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewAccessTrackingFS returns a FileSystem passing all operations through to
+// fs, and an AccessMap recording every path that is opened for reading,
+// including directories opened to be read, or passed to Stat through it.
+// Files opened for writing are not recorded.
+func NewAccessTrackingFS(fs absfs.FileSystem) (*FileSystem, *AccessMap, error) {
+	m := &AccessMap{entries: make(map[string]*accessEntry)}
+	var err error
+	if m.fs, err = NewFS(fs); err != nil {
+		return nil, nil, err
+	}
+	f, err := NewFS(fs, func(f *FileSystem) error {
+		f.accesses = m
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, m, nil
+}
+
+// An AccessMap records the paths accessed through a FileSystem returned by
+// NewAccessTrackingFS. Paths are recorded cleaned, but otherwise as given to
+// the FileSystem, so absolute paths should be used throughout for
+// NeverAccessed to be accurate. It is safe for concurrent use.
+type AccessMap struct {
+	fs *FileSystem
+
+	mu      sync.Mutex
+	entries map[string]*accessEntry
+}
+
+type accessEntry struct {
+	count int
+	last  time.Time
+}
+
+func (m *AccessMap) record(name string) {
+	name = path.Clean(name)
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entries[name]
+	if e == nil {
+		e = new(accessEntry)
+		m.entries[name] = e
+	}
+	e.count++
+	e.last = now
+}
+
+// Accessed returns the sorted paths that have been accessed.
+func (m *AccessMap) Accessed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.entries))
+	for name := range m.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Count returns the number of times name has been accessed.
+func (m *AccessMap) Count(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entries[path.Clean(name)]; e != nil {
+		return e.count
+	}
+	return 0
+}
+
+// LastAccess returns the time name was last accessed, or the zero time if it
+// has not been accessed.
+func (m *AccessMap) LastAccess(name string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entries[path.Clean(name)]; e != nil {
+		return e.last
+	}
+	return time.Time{}
+}
+
+// NeverAccessed walks the tree rooted at root and returns the sorted paths of
+// the regular files in it that have never been accessed. The walk itself is
+// not recorded.
+func (m *AccessMap) NeverAccessed(root string) ([]string, error) {
+	var names []string
+	err := m.fs.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && m.Count(name) == 0 {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,56 @@
+package ptfs_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewAccessTrackingFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfs.Mkdir("/assets", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"/assets/a", "/assets/b", "/assets/c"} {
+		writeFile(t, mfs, name, name)
+	}
+	fs, m, err := ptfs.NewAccessTrackingFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readAll(t, fs, "/assets/a")
+	readAll(t, fs, "/assets/a")
+	if _, err := fs.Stat("/assets/b"); err != nil {
+		t.Fatal(err)
+	}
+	// Opening for writing is not an access.
+	f, err := fs.OpenFile("/assets/c", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	never, err := m.NeverAccessed("/assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/assets/c"}; !reflect.DeepEqual(never, want) {
+		t.Fatalf("NeverAccessed = %v, want %v", never, want)
+	}
+	if want := []string{"/assets/a", "/assets/b"}; !reflect.DeepEqual(m.Accessed(), want) {
+		t.Fatalf("Accessed = %v, want %v", m.Accessed(), want)
+	}
+	if n := m.Count("/assets/a"); n != 2 {
+		t.Fatalf("Count(/assets/a) = %d, want 2", n)
+	}
+	if m.LastAccess("/assets/b").IsZero() {
+		t.Fatal("LastAccess(/assets/b) is zero")
+	}
+}
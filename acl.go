@@ -0,0 +1,54 @@
+package ptfs
+
+import "os"
+
+// An ACL is an access control list with the base entries of a POSIX ACL: the
+// permissions of the file's owner, its group, and everyone else.
+type ACL struct {
+	Owner, Group, Other AccessMode
+}
+
+// String returns the ACL in the short text form accepted by setfacl(1), for
+// example "u::rwx,g::r-x,o::---".
+func (a ACL) String() string {
+	return "u::" + a.Owner.rwx() + ",g::" + a.Group.rwx() + ",o::" + a.Other.rwx()
+}
+
+func (m AccessMode) rwx() string {
+	b := []byte("---")
+	if m&AccessRead != 0 {
+		b[0] = 'r'
+	}
+	if m&AccessWrite != 0 {
+		b[1] = 'w'
+	}
+	if m&AccessExecute != 0 {
+		b[2] = 'x'
+	}
+	return string(b)
+}
+
+// An ACLSetter is a filesystem that models permissions with access control
+// lists rather than mode bits.
+type ACLSetter interface {
+	SetACL(name string, acl ACL) error
+}
+
+// WithACLChmod makes Chmod set an ACL instead of mode bits when the underlying
+// filesystem implements ACLSetter. The owner, group and other permission bits
+// of the mode map to the Owner, Group and Other entries of the ACL, so 0750
+// becomes u::rwx,g::r-x,o::---. The setuid, setgid and sticky bits have no ACL
+// equivalent and are ignored. Filesystems that do not implement ACLSetter get
+// a plain Chmod.
+func WithACLChmod() Option {
+	return func(f *FileSystem) error {
+		f.aclChmod = true
+		return nil
+	}
+}
+
+// ModeACL returns the ACL equivalent to the permission bits of mode.
+func ModeACL(mode os.FileMode) ACL {
+	perm := AccessMode(mode.Perm())
+	return ACL{Owner: perm >> 6 & 7, Group: perm >> 3 & 7, Other: perm & 7}
+}
@@ -0,0 +1,61 @@
+package ptfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// aclFS records the ACLs set on it instead of changing mode bits.
+type aclFS struct {
+	absfs.FileSystem
+	acls map[string]ptfs.ACL
+}
+
+func (fs *aclFS) SetACL(name string, acl ptfs.ACL) error {
+	fs.acls[name] = acl
+	return nil
+}
+
+func TestWithACLChmod(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/f", "")
+	if err := mfs.Chmod("/f", 0644); err != nil {
+		t.Fatal(err)
+	}
+	afs := &aclFS{mfs, map[string]ptfs.ACL{}}
+	fs, err := ptfs.NewFS(afs, ptfs.WithACLChmod())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Chmod("/f", 0750); err != nil {
+		t.Fatal(err)
+	}
+	want := ptfs.ACL{
+		Owner: ptfs.AccessRead | ptfs.AccessWrite | ptfs.AccessExecute,
+		Group: ptfs.AccessRead | ptfs.AccessExecute,
+	}
+	if got := afs.acls["/f"]; got != want {
+		t.Errorf("ACL = %v, want %v", got, want)
+	}
+	if got := afs.acls["/f"].String(); got != "u::rwx,g::r-x,o::---" {
+		t.Errorf("ACL text = %q", got)
+	}
+	assertMode(t, fs, "/f", 0644)
+
+	// Without ACL support Chmod changes the mode bits.
+	plain, err := ptfs.NewFS(mfs, ptfs.WithACLChmod())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Chmod("/f", 0750); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, plain, "/f", 0750)
+}
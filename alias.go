@@ -0,0 +1,81 @@
+package ptfs
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// NewAliasFS returns a FileSystem presenting virtual aliases of paths in fs.
+// Each key of aliases is a path that is transparently redirected to its
+// value, along with everything below it, so with the alias
+// "/latest" => "/versions/v3", opening "/latest/file" opens
+// "/versions/v3/file". When aliases overlap the longest matching alias is
+// used. Aliases only apply to absolute paths, and unlike symbolic links they
+// need no support from the underlying filesystem. Getwd reports the working
+// directory in terms of the aliases.
+func NewAliasFS(fs absfs.FileSystem, aliases map[string]string, opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithAliases(aliases)}, opts...)...)
+}
+
+// WithAliases adds path aliases as described by NewAliasFS.
+func WithAliases(aliases map[string]string) Option {
+	var list []alias
+	for from, to := range aliases {
+		list = append(list, alias{path.Clean(from), path.Clean(to)})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return len(list[i].from) > len(list[j].from)
+	})
+	return WithRewrite(
+		func(op, name string) (string, error) { return mapAlias(list, name), nil },
+		func(name string) string { return unmapAlias(list, name) },
+	)
+}
+
+type alias struct {
+	from, to string
+}
+
+// mapAlias returns the name of a file in the underlying filesystem.
+func mapAlias(aliases []alias, name string) string {
+	if !path.IsAbs(name) {
+		return name
+	}
+	name = path.Clean(name)
+	for _, a := range aliases {
+		if rest, ok := cutPath(name, a.from); ok {
+			return a.to + rest
+		}
+	}
+	return name
+}
+
+// unmapAlias is the inverse of mapAlias.
+func unmapAlias(aliases []alias, name string) string {
+	best, bestTo := "", ""
+	for _, a := range aliases {
+		if rest, ok := cutPath(name, a.to); ok && len(a.to) > len(bestTo) {
+			best, bestTo = a.from+rest, a.to
+		}
+	}
+	if bestTo == "" {
+		return name
+	}
+	return best
+}
+
+// cutPath reports whether name is equal to or below dir, and returns the
+// rest of name after dir.
+func cutPath(name, dir string) (string, bool) {
+	if dir == "/" {
+		return name, strings.HasPrefix(name, "/")
+	}
+	rest, ok := strings.CutPrefix(name, dir)
+	if !ok || rest != "" && rest[0] != '/' {
+		return "", false
+	}
+	return rest, true
+}
@@ -0,0 +1,50 @@
+package ptfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestAliasFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/versions/v3/docs", "/versions/v2"} {
+		if err := mfs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, mfs, "/versions/v3/file", "v3")
+	writeFile(t, mfs, "/versions/v2/file", "v2")
+
+	fs, err := ptfs.NewAliasFS(mfs, map[string]string{
+		"/latest":     "/versions/v3",
+		"/latest/old": "/versions/v2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/latest/file"); got != "v3" {
+		t.Errorf("/latest/file = %q, want %q", got, "v3")
+	}
+	if got := readAll(t, fs, "/latest/old/file"); got != "v2" {
+		t.Errorf("/latest/old/file = %q, want %q", got, "v2")
+	}
+	if got := readAll(t, fs, "/versions/v2/file"); got != "v2" {
+		t.Errorf("unaliased path = %q, want %q", got, "v2")
+	}
+
+	if err := fs.Chdir("/latest/docs"); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := fs.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wd != "/latest/docs" {
+		t.Errorf("Getwd = %q, want %q", wd, "/latest/docs")
+	}
+}
@@ -0,0 +1,100 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// An Allowlist maps operation names to the path prefixes the operation is
+// permitted on. Operations are named after the FileSystem method performing
+// them, such as "Open", "Create" or "Remove", and the key "*" applies to every
+// operation. A path is within a prefix if it is equal to the prefix or is
+// below it. Paths are compared after cleaning, relative paths are not
+// resolved against the working directory.
+type Allowlist map[string][]string
+
+// WithAllowlist rejects any operation that the allowlist does not permit
+// with a *os.PathError wrapping os.ErrPermission.
+func WithAllowlist(list Allowlist) Option {
+	return func(f *FileSystem) error {
+		f.allow = list
+		return nil
+	}
+}
+
+// WithDenialLog calls log the first time an operation is rejected by the
+// allowlist for a given operation and directory. Later denials of the same
+// operation in the same directory are not logged until ResetDenialLog is
+// called, so a program repeatedly attempting a forbidden operation does not
+// flood the log.
+func WithDenialLog(log func(op, name string)) Option {
+	return func(f *FileSystem) error {
+		f.denied = log
+		return nil
+	}
+}
+
+// ResetDenialLog forgets which denials have been logged, so the next denial
+// of each operation is logged again.
+func (f *FileSystem) ResetDenialLog() {
+	f.denials.reset()
+}
+
+func (f *FileSystem) checkAllowed(op, name string) error {
+	if f.allow == nil {
+		return nil
+	}
+	name = path.Clean(name)
+	if f.allow.permits(op, name) {
+		return nil
+	}
+	if f.denied != nil && f.denials.first(op, path.Dir(name)) {
+		f.denied(op, name)
+	}
+	return &os.PathError{Op: op, Path: name, Err: os.ErrPermission}
+}
+
+// permits reports whether the list permits op on the cleaned path name.
+func (l Allowlist) permits(op, name string) bool {
+	return within(name, l[op]) || within(name, l["*"])
+}
+
+// within reports whether name is equal to or below one of the prefixes.
+func within(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = path.Clean(prefix)
+		if name == prefix || prefix == "/" && strings.HasPrefix(name, "/") ||
+			strings.HasPrefix(name, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+type denialLog struct {
+	mu   sync.Mutex
+	seen map[[2]string]bool
+}
+
+// first records a denial, and reports whether it is the first for op and dir.
+func (l *denialLog) first(op, dir string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := [2]string{op, dir}
+	if l.seen[key] {
+		return false
+	}
+	if l.seen == nil {
+		l.seen = make(map[[2]string]bool)
+	}
+	l.seen[key] = true
+	return true
+}
+
+func (l *denialLog) reset() {
+	l.mu.Lock()
+	l.seen = nil
+	l.mu.Unlock()
+}
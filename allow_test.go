@@ -0,0 +1,54 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestAllowlist(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged []string
+	fs, err := ptfs.NewFS(mfs,
+		ptfs.WithAllowlist(ptfs.Allowlist{
+			"*":      {"/public"},
+			"Create": {"/tmp"},
+		}),
+		ptfs.WithDenialLog(func(op, name string) {
+			logged = append(logged, op+" "+name)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfs.Mkdir("/tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Create("/tmp/ok")
+	if err != nil {
+		t.Fatalf("allowed Create: %v", err)
+	}
+	f.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := fs.Open("/tmp/ok"); !errors.Is(err, os.ErrPermission) {
+			t.Fatalf("denied Open: error = %v, want ErrPermission", err)
+		}
+	}
+	if len(logged) != 1 || logged[0] != "Open /tmp/ok" {
+		t.Errorf("logged %q, want a single Open denial", logged)
+	}
+
+	fs.ResetDenialLog()
+	fs.Open("/tmp/ok")
+	if len(logged) != 2 {
+		t.Errorf("logged %d denials after reset, want 2", len(logged))
+	}
+}
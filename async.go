@@ -0,0 +1,153 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// An AsyncFS is a FileSystem whose modifying operations run in the
+// background. Each of WriteFile, Mkdir, MkdirAll, Remove, RemoveAll, Rename,
+// Truncate, Chmod, Chtimes and Chown returns at once with a channel that
+// receives the operation's error, or nil, when it completes. The channel is
+// buffered, so results that are not wanted may be ignored. All other
+// methods, including Open, OpenFile and Create, are those of the embedded
+// FileSystem and run synchronously.
+//
+// Operations on the same path run in the order they were submitted, and
+// Rename is ordered with the operations on both of its paths. Operations on
+// different paths may run in any order and concurrently, even when one path
+// is below the other, so to create a file in a new directory wait for the
+// Mkdir to complete before submitting the WriteFile. Synchronous reads do
+// not wait for pending operations; call Drain first to see their effects.
+type AsyncFS struct {
+	*FileSystem
+
+	sem     chan struct{}
+	pending sync.WaitGroup
+
+	mu    sync.Mutex
+	tails map[string]chan struct{} // completion of the last operation per path
+}
+
+// NewAsyncFS returns an AsyncFS running at most workers operations on fs at
+// a time. A workers of less than 1 is treated as 1.
+func NewAsyncFS(fs absfs.FileSystem, workers int, opts ...Option) (*AsyncFS, error) {
+	f, err := NewFS(fs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &AsyncFS{
+		FileSystem: f,
+		sem:        make(chan struct{}, max(workers, 1)),
+		tails:      make(map[string]chan struct{}),
+	}, nil
+}
+
+// Drain waits until every operation submitted so far has completed.
+func (f *AsyncFS) Drain() {
+	f.pending.Wait()
+}
+
+// submit runs op in the background after the operations previously
+// submitted for names have completed.
+func (f *AsyncFS) submit(op func() error, names ...string) <-chan error {
+	result := make(chan error, 1)
+	done := make(chan struct{})
+	keys := make([]string, len(names))
+	var prev []chan struct{}
+	f.mu.Lock()
+	for i, name := range names {
+		keys[i] = f.key(name)
+		if tail, ok := f.tails[keys[i]]; ok {
+			prev = append(prev, tail)
+		}
+		f.tails[keys[i]] = done
+	}
+	f.mu.Unlock()
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		for _, tail := range prev {
+			<-tail
+		}
+		f.sem <- struct{}{}
+		result <- op()
+		<-f.sem
+		close(done)
+		f.mu.Lock()
+		for _, key := range keys {
+			if f.tails[key] == done {
+				delete(f.tails, key)
+			}
+		}
+		f.mu.Unlock()
+	}()
+	return result
+}
+
+// key returns the absolute path of name, by which operations are ordered.
+func (f *AsyncFS) key(name string) string {
+	if path.IsAbs(name) {
+		return path.Clean(name)
+	}
+	dir, _ := f.FileSystem.Getwd()
+	return path.Join(dir, name)
+}
+
+// WriteFile writes data to the named file, creating it with permissions perm
+// if necessary, and truncating it otherwise. data is copied, so it may be
+// modified once WriteFile returns.
+func (f *AsyncFS) WriteFile(name string, data []byte, perm os.FileMode) <-chan error {
+	data = append([]byte(nil), data...)
+	return f.submit(func() error { return f.FileSystem.writeFile(name, data, perm) }, name)
+}
+
+// Mkdir creates a directory.
+func (f *AsyncFS) Mkdir(name string, perm os.FileMode) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Mkdir(name, perm) }, name)
+}
+
+// MkdirAll creates a directory and any missing parents.
+func (f *AsyncFS) MkdirAll(name string, perm os.FileMode) <-chan error {
+	return f.submit(func() error { return f.FileSystem.MkdirAll(name, perm) }, name)
+}
+
+// Remove removes a file or empty directory.
+func (f *AsyncFS) Remove(name string) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Remove(name) }, name)
+}
+
+// RemoveAll removes a path and any children it contains.
+func (f *AsyncFS) RemoveAll(name string) <-chan error {
+	return f.submit(func() error { return f.FileSystem.RemoveAll(name) }, name)
+}
+
+// Rename renames oldname to newname.
+func (f *AsyncFS) Rename(oldname, newname string) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Rename(oldname, newname) }, oldname, newname)
+}
+
+// Truncate changes the size of the named file.
+func (f *AsyncFS) Truncate(name string, size int64) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Truncate(name, size) }, name)
+}
+
+// Chmod changes the mode of the named file.
+func (f *AsyncFS) Chmod(name string, mode os.FileMode) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Chmod(name, mode) }, name)
+}
+
+// Chtimes changes the access and modification times of the named file.
+func (f *AsyncFS) Chtimes(name string, atime, mtime time.Time) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Chtimes(name, atime, mtime) }, name)
+}
+
+// Chown changes the owner and group ids of the named file.
+func (f *AsyncFS) Chown(name string, uid, gid int) <-chan error {
+	return f.submit(func() error { return f.FileSystem.Chown(name, uid, gid) }, name)
+}
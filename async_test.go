@@ -0,0 +1,67 @@
+package ptfs_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewAsyncFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := ptfs.NewThrottledFS(mfs, 2*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewAsyncFS(slow, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-fs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes to the same path apply in order; the last one wins.
+	for i := 0; i < 10; i++ {
+		fs.WriteFile("/dir/log", []byte(fmt.Sprint(i)), 0644)
+	}
+	var results []<-chan error
+	for i := 0; i < 10; i++ {
+		results = append(results, fs.WriteFile(fmt.Sprintf("/dir/%d", i), []byte("x"), 0644))
+	}
+	fs.Rename("/dir/0", "/dir/zero")
+	fs.Drain()
+
+	for _, result := range results {
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Error(err)
+			}
+		default:
+			t.Fatal("Drain returned before an operation completed")
+		}
+	}
+	if got := readAll(t, fs, "/dir/log"); got != "9" {
+		t.Errorf("/dir/log = %q, want %q", got, "9")
+	}
+	names, err := readDirNames(mfs, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 11 {
+		t.Errorf("got %d files, want 11: %v", len(names), names)
+	}
+	if got := readAll(t, fs, "/dir/zero"); got != "x" {
+		t.Errorf("/dir/zero = %q, want %q", got, "x")
+	}
+
+	if err := <-fs.Remove("/missing"); err == nil {
+		t.Error("Remove of a missing file succeeded")
+	}
+}
@@ -0,0 +1,92 @@
+package ptfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/absfs/absfs"
+)
+
+// WriteFileAtomic writes data to the named file, replacing it atomically
+// with a temporary file as ReplaceFromReader does.
+func (f *FileSystem) WriteFileAtomic(name string, data []byte, perm os.FileMode) error {
+	return f.ReplaceFromReader(name, bytes.NewReader(data), perm)
+}
+
+// ReplaceFromReader replaces the named file with the content of r. The content
+// is streamed to a temporary file in the same directory, synced, and renamed
+// over name, so readers see either the old or the new content and never a
+// partial file. The replaced file has permissions perm. If an error occurs
+// the temporary file is removed and name is left unchanged.
+func (f *FileSystem) ReplaceFromReader(name string, r io.Reader, perm os.FileMode) error {
+	tmpName, err := f.writeTemp(name, r, perm)
+	if err != nil {
+		return err
+	}
+	if err := f.Rename(tmpName, name); err != nil {
+		f.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// writeTemp writes the content of r to a new temporary file beside name with
+// permissions perm, syncs it, and returns its name. If an error occurs the
+// temporary file is removed.
+func (f *FileSystem) writeTemp(name string, r io.Reader, perm os.FileMode) (string, error) {
+	tmp, tmpName, err := f.createTemp(path.Dir(name), path.Base(name), perm)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.Chmod(tmpName, perm)
+	}
+	if err != nil {
+		f.Remove(tmpName)
+		return "", err
+	}
+	return tmpName, nil
+}
+
+// tempName returns a random name in dir beginning with prefix.
+func tempName(dir, prefix string) string {
+	return path.Join(dir, "."+prefix+".tmp"+strconv.FormatUint(uint64(rand.Uint32()), 10))
+}
+
+// createTemp creates a new file in dir with a unique name beginning with
+// prefix, and returns it opened for writing along with its name.
+func (f *FileSystem) createTemp(dir, prefix string, perm os.FileMode) (absfs.File, string, error) {
+	for try := 0; ; try++ {
+		name := tempName(dir, prefix)
+		file, err := f.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, os.ErrExist) && try < 10000 {
+			continue
+		}
+		return file, name, err
+	}
+}
+
+// mkdirTemp creates a new directory in dir with a unique name beginning with
+// prefix, and returns its name.
+func (f *FileSystem) mkdirTemp(dir, prefix string) (string, error) {
+	for try := 0; ; try++ {
+		name := tempName(dir, prefix)
+		err := f.Mkdir(name, 0700)
+		if errors.Is(err, os.ErrExist) && try < 10000 {
+			continue
+		}
+		return name, err
+	}
+}
@@ -0,0 +1,60 @@
+package ptfs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// checkReader calls check before its first read.
+type checkReader struct {
+	io.Reader
+	check func()
+}
+
+func (r *checkReader) Read(p []byte) (int, error) {
+	if r.check != nil {
+		r.check()
+		r.check = nil
+	}
+	return r.Reader.Read(p)
+}
+
+func TestReplaceFromReader(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/config", "old")
+
+	r := &checkReader{
+		Reader: bytes.NewReader([]byte("new")),
+		check: func() {
+			if got := readAll(t, fs, "/config"); got != "old" {
+				t.Errorf("content while replacing = %q, want %q", got, "old")
+			}
+		},
+	}
+	if err := fs.ReplaceFromReader("/config", r, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/config"); got != "new" {
+		t.Errorf("content = %q, want %q", got, "new")
+	}
+	assertMode(t, fs, "/config", 0600)
+
+	entries, err := readDirNames(fs, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory entries = %q, want only the replaced file", entries)
+	}
+}
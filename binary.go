@@ -0,0 +1,54 @@
+package ptfs
+
+import (
+	"bytes"
+	"io"
+)
+
+// defaultBinarySample is the number of bytes IsBinary reads by default, the
+// same as git reads to decide whether a file is binary.
+const defaultBinarySample = 8000
+
+// WithBinarySample sets the number of bytes at the start of a file that
+// IsBinary examines. The default is 8000.
+func WithBinarySample(n int) Option {
+	return func(f *FileSystem) error {
+		f.binarySample = n
+		return nil
+	}
+}
+
+// IsBinary reports whether the named file appears to hold binary data rather
+// than text, judging by its first 8000 bytes or the number set with
+// WithBinarySample. The file is binary if those bytes contain a NUL byte, as
+// git decides, or if more than 30% of them are control characters other
+// than tab, newline, vertical tab, form feed, carriage return, backspace and
+// escape. Bytes of 0x80 and above are not counted, so UTF-8 text of any
+// language is text. Empty files are text.
+func (f *FileSystem) IsBinary(name string) (bool, error) {
+	n := f.binarySample
+	if n <= 0 {
+		n = defaultBinarySample
+	}
+	buf := make([]byte, n)
+	n, err := f.ReadFileInto(name, buf)
+	if err != nil && err != io.ErrShortBuffer {
+		return false, err
+	}
+	return isBinary(buf[:n]), nil
+}
+
+func isBinary(p []byte) bool {
+	if bytes.IndexByte(p, 0) >= 0 {
+		return true
+	}
+	control := 0
+	for _, b := range p {
+		switch {
+		case b == '\t', b == '\n', b == '\v', b == '\f', b == '\r', b == '\b', b == 0x1b:
+		case b < 0x20, b == 0x7f:
+			control++
+		}
+	}
+	return control*10 > len(p)*3
+}
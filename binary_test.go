@@ -0,0 +1,56 @@
+package ptfs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestIsBinary(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/nul", "abc\x00def")
+	writeFile(t, fs, "/control", "\x01\x02\x03\x04 ab")
+	writeFile(t, fs, "/utf8", "héllo, 世界\r\n\tпривет\n")
+	writeFile(t, fs, "/empty", "")
+	writeFile(t, fs, "/late", strings.Repeat("a", 9000)+"\x00")
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"/nul", true},
+		{"/control", true},
+		{"/utf8", false},
+		{"/empty", false},
+		{"/late", false}, // the NUL is past the sample
+	}
+	for _, tt := range tests {
+		got, err := fs.IsBinary(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("IsBinary(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	large, err := ptfs.NewFS(mfs, ptfs.WithBinarySample(1<<14))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := large.IsBinary("/late"); err != nil || !got {
+		t.Errorf("IsBinary(/late) with a larger sample = %v, %v, want true", got, err)
+	}
+	if _, err := fs.IsBinary("/missing"); err == nil {
+		t.Error("IsBinary of a missing file succeeded")
+	}
+}
@@ -0,0 +1,48 @@
+package ptfs
+
+import (
+	"os"
+
+	"github.com/absfs/absfs"
+)
+
+// Capabilities reports which optional interfaces the filesystem underlying a
+// FileSystem implements.
+type Capabilities struct {
+	// Symlinks is true if the filesystem supports symbolic links.
+	Symlinks bool
+
+	// StatFS is true if the filesystem, after unwrapping pass through
+	// filesystems, implements StatFSer.
+	StatFS bool
+}
+
+// Capabilities returns the optional interfaces implemented by the underlying
+// filesystem. They are determined once when the FileSystem is created.
+func (f *FileSystem) Capabilities() Capabilities {
+	return Capabilities{
+		Symlinks: f.caps.lstater != nil,
+		StatFS:   f.caps.statfser != nil,
+	}
+}
+
+type lstater interface {
+	Lstat(name string) (os.FileInfo, error)
+}
+
+// capabilities caches the result of type asserting the underlying filesystem
+// to optional interfaces, so operations do not repeat the assertions. A nil
+// field means the interface is not implemented.
+type capabilities struct {
+	lstater   lstater
+	statfser  StatFSer
+	aclSetter ACLSetter
+}
+
+func probeCapabilities(fs absfs.FileSystem) capabilities {
+	var caps capabilities
+	caps.lstater, _ = fs.(lstater)
+	caps.statfser, _ = DeepUnwrapFS(fs).(StatFSer)
+	caps.aclSetter, _ = fs.(ACLSetter)
+	return caps
+}
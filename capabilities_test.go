@@ -0,0 +1,60 @@
+package ptfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestCapabilities(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, base := range []absfs.FileSystem{mfs, statFS{mfs}, streamFS{mfs}} {
+		fs, err := ptfs.NewFS(base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, symlinks := base.(absfs.SymlinkFileSystem)
+		_, statfs := base.(ptfs.StatFSer)
+		want := ptfs.Capabilities{Symlinks: symlinks, StatFS: statfs}
+		if got := fs.Capabilities(); got != want {
+			t.Errorf("%T: Capabilities = %+v, want %+v", base, got, want)
+		}
+	}
+}
+
+func BenchmarkStatFS(b *testing.B) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		b.Fatal(err)
+	}
+	inner, err := ptfs.NewFS(statFS{mfs})
+	if err != nil {
+		b.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(inner)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// cached uses the assertion made when the FileSystem was created, assert
+	// repeats it on every call, as StatFS did before capabilities were cached.
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fs.StatFS()
+		}
+	})
+	b.Run("assert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if s, ok := ptfs.DeepUnwrapFS(fs).(ptfs.StatFSer); ok {
+				s.StatFS()
+			}
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package ptfs
+
+import (
+	"fmt"
+
+	"github.com/absfs/absfs"
+)
+
+// WithName labels the FileSystem with a human readable name reported by Name
+// and Chain, to tell layers apart when debugging composed wrappers. It
+// returns f so that calls can be chained, and should be called before f is
+// shared between goroutines.
+func (f *FileSystem) WithName(name string) *FileSystem {
+	f.name = name
+	return f
+}
+
+// Name returns the label given by WithName, or "" if there is none. It is
+// unrelated to the names of files.
+func (f *FileSystem) Name() string {
+	return f.name
+}
+
+// Chain describes the stack of filesystems below f, starting with f itself.
+// Each pass through filesystem is described by its name, or its type if it
+// has none, and the innermost filesystem, which is not a pass through
+// filesystem, by its type.
+func (f *FileSystem) Chain() []string {
+	var chain []string
+	var fs absfs.FileSystem = f
+	for {
+		switch pfs := fs.(type) {
+		case *FileSystem:
+			chain = append(chain, label(pfs.name, pfs))
+			fs = pfs.fs
+		case *SymlinkFileSystem:
+			chain = append(chain, label(pfs.fs.name, pfs))
+			fs = pfs.sfs
+		default:
+			return append(chain, label("", fs))
+		}
+	}
+}
+
+func label(name string, fs absfs.FileSystem) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", fs)
+}
@@ -0,0 +1,41 @@
+package ptfs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestChain(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner.WithName("inner")
+	outer, err := ptfs.NewFS(inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := outer.WithName("outer").Name(); got != "outer" {
+		t.Fatalf("Name() = %q, want %q", got, "outer")
+	}
+
+	want := []string{"outer", "inner", "*memfs.FileSystem"}
+	if got := outer.Chain(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Chain() = %q, want %q", got, want)
+	}
+
+	unnamed, err := ptfs.NewFS(outer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := unnamed.Chain()[0]; got != "*ptfs.FileSystem" {
+		t.Fatalf("Chain()[0] of an unnamed layer = %q, want %q", got, "*ptfs.FileSystem")
+	}
+}
@@ -0,0 +1,169 @@
+package ptfs
+
+import (
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/absfs/absfs"
+)
+
+// CopyFile copies the content of the regular file src to dst, creating or
+// truncating dst. A new dst is created with the permissions of src. With
+// WithSparseCopy, blocks of zeros are skipped rather than written.
+func (f *FileSystem) CopyFile(src, dst string) error {
+	in, err := f.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := f.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if f.sparseBlock > 0 && out.(*File).SupportsSeek() {
+		err = copySparse(out, in, f.sparseBlock)
+	} else {
+		_, err = io.Copy(out, in)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// CopyTree copies the tree rooted at the directory src to dst, creating
+// dst and its subdirectories with the permissions of their sources, and
+// copying regular files with CopyFile. Symbolic links and other special
+// files are skipped, and existing files in dst are replaced. With
+// WithMaxDepth, CopyTree fails with an error wrapping ErrMaxDepthExceeded,
+// after copying what it visited before, if src is deeper than allowed.
+func (f *FileSystem) CopyTree(src, dst string) error {
+	return f.Walk(src, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := f.Rel(src, name)
+		if err != nil {
+			return err
+		}
+		target := f.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return f.MkdirAll(target, info.Mode().Perm())
+		case info.Mode().IsRegular():
+			return f.CopyFile(name, target)
+		}
+		return nil
+	})
+}
+
+// CopyMeta copies the permissions and mode bits, the modification time and,
+// when they can be determined, the owner and group of src to the existing
+// file dst, leaving the content of dst unchanged. FileInfo does not report
+// access times, so the access time of dst is set to the modification time.
+// Operations that dst's filesystem does not support are skipped.
+func (f *FileSystem) CopyMeta(src, dst string) error {
+	info, err := f.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := f.Chmod(dst, info.Mode()); err != nil && !isNotSupported(err) {
+		return err
+	}
+	if uid, gid, ok := owner(info); ok {
+		if err := f.Chown(dst, uid, gid); err != nil && !isNotSupported(err) {
+			return err
+		}
+	}
+	mtime := info.ModTime()
+	if err := f.Chtimes(dst, mtime, mtime); err != nil && !isNotSupported(err) {
+		return err
+	}
+	return nil
+}
+
+// owner returns the owner and group of a file from the Uid and Gid fields of
+// info.Sys(), such as those of a *syscall.Stat_t, if present.
+func owner(info os.FileInfo) (uid, gid int, ok bool) {
+	v := reflect.ValueOf(info.Sys())
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, 0, false
+	}
+	u, g := v.FieldByName("Uid"), v.FieldByName("Gid")
+	if !u.IsValid() || !g.IsValid() || !u.CanUint() || !g.CanUint() {
+		return 0, 0, false
+	}
+	return int(u.Uint()), int(g.Uint()), true
+}
+
+// WithSparseCopy makes CopyFile preserve holes in sparse files. The source is
+// copied in blocks of size bytes, and blocks that are entirely zero are
+// skipped by seeking past them instead of being written, which leaves a hole
+// in the copy on filesystems that support sparse files. Either way the copy
+// reads back identical to the source. If the destination file does not
+// support Seek, the zeros are written normally. A size of 0 or less uses
+// blocks of 4096 bytes.
+func WithSparseCopy(size int) Option {
+	return func(f *FileSystem) error {
+		if size <= 0 {
+			size = 4096
+		}
+		f.sparseBlock = size
+		return nil
+	}
+}
+
+// copySparse copies r to w in blocks of size bytes, seeking past blocks of
+// zeros.
+func copySparse(w absfs.File, r io.Reader, size int) error {
+	buf := make([]byte, size)
+	var off int64
+	hole := false
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if isZero(buf[:n]) {
+				if _, err := w.Seek(int64(n), io.SeekCurrent); err != nil {
+					return err
+				}
+				hole = true
+			} else {
+				if _, err := w.Write(buf[:n]); err != nil {
+					return err
+				}
+				hole = false
+			}
+			off += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if hole {
+		// Seeking past the end does not extend the file, so set the size
+		// of a file ending in a hole explicitly.
+		return w.Truncate(off)
+	}
+	return nil
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
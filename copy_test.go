@@ -0,0 +1,96 @@
+package ptfs_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestCopyMeta(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/src", "source")
+	writeFile(t, fs, "/dst", "destination")
+	mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if err := fs.Chmod("/src", 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chtimes("/src", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.CopyMeta("/src", "/dst"); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, fs, "/dst", 0600)
+	assertModTime(t, fs, "/dst", mtime)
+	if got := readAll(t, fs, "/dst"); got != "destination" {
+		t.Fatalf("dst content = %q, want it unchanged", got)
+	}
+
+	if err := fs.CopyMeta("/missing", "/dst"); err == nil {
+		t.Fatal("CopyMeta from a missing file succeeded")
+	}
+}
+
+func TestWithSparseCopy(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithSparseCopy(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{
+		"/middle": "head" + strings.Repeat("\x00", 64<<10) + "tail",
+		"/end":    "head" + strings.Repeat("\x00", 64<<10),
+		"/zeros":  strings.Repeat("\x00", 10000),
+		"/empty":  "",
+	} {
+		writeFile(t, fs, name, content)
+		if err := fs.CopyFile(name, name+".copy"); err != nil {
+			t.Fatal(err)
+		}
+		if got := readAll(t, fs, name+".copy"); got != content {
+			t.Errorf("copy of %s differs: got %d bytes, want %d", name, len(got), len(content))
+		}
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/src/sub", 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/src/a", "a")
+	writeFile(t, fs, "/src/sub/b", "b")
+
+	if err := fs.CopyTree("/src", "/dst"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/dst/a"); got != "a" {
+		t.Errorf("/dst/a = %q, want %q", got, "a")
+	}
+	if got := readAll(t, fs, "/dst/sub/b"); got != "b" {
+		t.Errorf("/dst/sub/b = %q, want %q", got, "b")
+	}
+	assertMode(t, fs, "/dst/sub", 0750)
+}
@@ -0,0 +1,57 @@
+package ptfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IncrementCounter adds delta to the integer stored as decimal text in the
+// named file and returns the new value. A missing file counts as 0. The new
+// value is written atomically as WriteFileAtomic does, and the read, add and
+// write are done while holding the exclusive lock on name used by
+// OpenExclusive, so concurrent increments through the same FileSystem value
+// are never lost. Increments through other FileSystem values or other
+// processes are not coordinated.
+func (f *FileSystem) IncrementCounter(name string, delta int64) (int64, error) {
+	unlock := f.locks.lock(name, true)
+	defer unlock()
+
+	n, err := f.readCounter(name)
+	if err != nil {
+		return 0, err
+	}
+	n += delta
+	if err := f.WriteFileAtomic(name, []byte(strconv.FormatInt(n, 10)+"\n"), 0644); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// readCounter returns the integer stored in the named file, or 0 if it does
+// not exist.
+func (f *FileSystem) readCounter(name string) (int64, error) {
+	file, err := f.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, &os.PathError{Op: "increment", Path: name, Err: err}
+	}
+	return n, nil
+}
@@ -0,0 +1,45 @@
+package ptfs_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestIncrementCounter(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := fs.IncrementCounter("/seq", 5)
+	if err != nil || n != 5 {
+		t.Fatalf("IncrementCounter = %d, %v, want 5, nil", n, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := fs.IncrementCounter("/seq", 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := readAll(t, fs, "/seq"); got != "25\n" {
+		t.Fatalf("counter = %q, want %q", got, "25\n")
+	}
+
+	writeFile(t, fs, "/bad", "x")
+	if _, err := fs.IncrementCounter("/bad", 1); err == nil {
+		t.Fatal("IncrementCounter of a non-integer file succeeded")
+	}
+}
@@ -0,0 +1,90 @@
+package ptfs
+
+import (
+	"os"
+	"time"
+)
+
+// WithIODeadline limits the time each Read, Write, ReadAt, WriteAt and
+// WriteString on files opened through the FileSystem may take to d. An
+// operation taking longer fails with an error wrapping os.ErrDeadlineExceeded.
+// The deadline of an open file may be changed with SetIODeadline.
+//
+// The underlying operation cannot be cancelled and keeps running in its own
+// goroutine after the deadline is exceeded. It reads into and writes from a
+// private copy of the caller's buffer, so the caller may reuse the buffer,
+// and the next operation on the file waits, within its own deadline, for it
+// to finish, so at most one abandoned operation per file is outstanding.
+//
+// An abandoned operation still takes effect: an abandoned Read consumes the
+// data it reads, which is discarded, and an abandoned Write may store its
+// data after the caller was told it failed. The file offset is therefore
+// indeterminate after a timeout, and a caller continuing to use the file
+// should Seek to a known offset first.
+//
+// Close waits for an abandoned operation to finish before closing the file,
+// without any deadline, so Close blocks for as long as the underlying
+// operation does, however long that is.
+func WithIODeadline(d time.Duration) Option {
+	return func(f *FileSystem) error {
+		f.ioDeadline = d
+		return nil
+	}
+}
+
+// SetIODeadline sets the time each I/O operation on the file may take, as
+// described by WithIODeadline. A d of 0 removes the deadline. It must not be
+// called concurrently with I/O on the file.
+func (f *File) SetIODeadline(d time.Duration) {
+	f.ioDeadline = d
+}
+
+type ioResult struct {
+	n   int
+	err error
+}
+
+// deadlineIO runs fn on buf, returning an error if it does not complete
+// within the I/O deadline of the file.
+func (f *File) deadlineIO(op string, buf []byte, fn func([]byte) (int, error)) (int, error) {
+	timer := time.NewTimer(f.ioDeadline)
+	defer timer.Stop()
+	if f.pending != nil {
+		select {
+		case <-f.pending:
+			f.pending = nil
+		case <-timer.C:
+			return 0, f.deadlineExceeded(op)
+		}
+	}
+
+	done := make(chan ioResult, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		n, err := fn(buf)
+		done <- ioResult{n, err}
+	}()
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timer.C:
+		f.pending = finished
+		return 0, f.deadlineExceeded(op)
+	}
+}
+
+func (f *File) deadlineExceeded(op string) error {
+	return &os.PathError{Op: op, Path: f.Name(), Err: os.ErrDeadlineExceeded}
+}
+
+func (f *File) readDeadline(op string, p []byte, fn func([]byte) (int, error)) (int, error) {
+	buf := make([]byte, len(p))
+	n, err := f.deadlineIO(op, buf, fn)
+	copy(p, buf[:n])
+	return n, err
+}
+
+func (f *File) writeDeadline(op string, p []byte, fn func([]byte) (int, error)) (int, error) {
+	return f.deadlineIO(op, append([]byte(nil), p...), fn)
+}
@@ -0,0 +1,126 @@
+package ptfs_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// slowFS opens files whose reads take delay.
+type slowFS struct {
+	absfs.FileSystem
+	delay time.Duration
+}
+
+func (fs slowFS) Open(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return slowFile{f, fs.delay}, nil
+}
+
+type slowFile struct {
+	absfs.File
+	delay time.Duration
+}
+
+func (f slowFile) Read(p []byte) (int, error) {
+	time.Sleep(f.delay)
+	return f.File.Read(p)
+}
+
+func TestWithIODeadline(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(slowFS{mfs, 50 * time.Millisecond}, ptfs.WithIODeadline(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/data", "data")
+
+	f, err := fs.Open("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	buf := make([]byte, 4)
+	if _, err := f.Read(buf); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("slow Read = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	// The next read waits for the abandoned one, which consumed the content,
+	// before starting its own.
+	f.(*ptfs.File).SetIODeadline(time.Second)
+	if n, err := f.Read(buf); n != 0 || err != io.EOF {
+		t.Fatalf("Read after an abandoned read = %d, %v, want 0, EOF", n, err)
+	}
+}
+
+// slowWriteFS creates files whose writes take delay, and which record
+// whether they are closed, without synchronization, so that a Close racing a
+// Write is reported by the race detector.
+type slowWriteFS struct {
+	absfs.FileSystem
+	delay time.Duration
+}
+
+func (fs slowWriteFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &slowWriteFile{File: f, delay: fs.delay}, nil
+}
+
+type slowWriteFile struct {
+	absfs.File
+	delay  time.Duration
+	closed bool
+}
+
+func (f *slowWriteFile) Write(p []byte) (int, error) {
+	time.Sleep(f.delay)
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	return f.File.Write(p)
+}
+
+func (f *slowWriteFile) Close() error {
+	f.closed = true
+	return f.File.Close()
+}
+
+func TestIODeadlineClose(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(slowWriteFS{mfs, 50 * time.Millisecond}, ptfs.WithIODeadline(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Create("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("data")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("slow Write = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The abandoned write finished before the file was closed.
+	if got := readAll(t, mfs, "/data"); got != "data" {
+		t.Errorf("content = %q, want %q", got, "data")
+	}
+}
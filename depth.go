@@ -0,0 +1,112 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"path"
+)
+
+// ErrMaxDepthExceeded is returned, wrapped in a *os.PathError, by recursive
+// operations on trees deeper than allowed by WithMaxDepth.
+var ErrMaxDepthExceeded = errors.New("maximum directory depth exceeded")
+
+// WithMaxDepth limits recursive operations to n levels below the path they
+// operate on, guarding against pathologically deep trees. MkdirAll refuses to
+// create more than n levels of missing directories, Walk, WalkDirs and
+// CopyTree stop when they would descend more than n levels below their root,
+// and RemoveAll refuses to remove a tree with entries more than n levels
+// below the path removed. Each fails with an error wrapping
+// ErrMaxDepthExceeded. RemoveAll reads the whole tree to check its depth
+// before removing anything, so a tree that is too deep is left intact. The
+// default, or an n of 0, is unlimited.
+func WithMaxDepth(n int) Option {
+	return func(f *FileSystem) error {
+		f.maxDepth = n
+		return nil
+	}
+}
+
+func (f *FileSystem) depthExceeded(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: ErrMaxDepthExceeded}
+}
+
+// checkMkdirDepth returns an error if creating name with MkdirAll would
+// create more levels of directories than allowed by WithMaxDepth.
+func (f *FileSystem) checkMkdirDepth(name string) error {
+	missing := 0
+	for dir := f.Join(name); ; {
+		if _, err := f.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+			// Other errors are left to MkdirAll to report.
+			return nil
+		}
+		if missing++; missing > f.maxDepth {
+			return f.depthExceeded("mkdir", name)
+		}
+		parent := f.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// removeAllLimited removes the tree rooted at name as RemoveAll does, within
+// the depth allowed by WithMaxDepth. Like os.RemoveAll, it succeeds if name
+// does not exist.
+func (f *FileSystem) removeAllLimited(name string) error {
+	info, err := f.lstat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := f.checkTreeDepth(name, info, 0); err != nil {
+		return err
+	}
+	return f.removeAllDepth(name, info)
+}
+
+// checkTreeDepth returns an error if the tree rooted at name, whose FileInfo
+// is info and which is depth levels below the path passed to RemoveAll, has
+// entries deeper than allowed by WithMaxDepth. It only reads the tree, and
+// does not descend beyond the limit.
+func (f *FileSystem) checkTreeDepth(name string, info os.FileInfo, depth int) error {
+	if !info.IsDir() {
+		return nil
+	}
+	infos, err := f.readDir(name)
+	if err != nil {
+		return err
+	}
+	if len(infos) > 0 && depth >= f.maxDepth {
+		return f.depthExceeded("removeall", path.Join(name, infos[0].Name()))
+	}
+	for _, child := range infos {
+		if err := f.checkTreeDepth(path.Join(name, child.Name()), child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeAllDepth removes the tree rooted at name, whose FileInfo is info,
+// depth first.
+func (f *FileSystem) removeAllDepth(name string, info os.FileInfo) error {
+	if info.IsDir() {
+		infos, err := f.readDir(name)
+		if err != nil {
+			return err
+		}
+		for _, child := range infos {
+			if err := f.removeAllDepth(path.Join(name, child.Name()), child); err != nil {
+				return err
+			}
+		}
+	}
+	p, err := f.mapPath("RemoveAll", name)
+	if err != nil {
+		return err
+	}
+	return f.fs.Remove(p)
+}
@@ -0,0 +1,76 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWithMaxDepth(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithMaxDepth(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.MkdirAll("/a/b/c", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/x/1/2/3", 0755); !errors.Is(err, ptfs.ErrMaxDepthExceeded) {
+		t.Fatalf("MkdirAll beyond the limit = %v, want %v", err, ptfs.ErrMaxDepthExceeded)
+	}
+	// Depth is counted from the deepest existing directory.
+	if err := fs.MkdirAll("/a/b/c/d/e", 0755); err != nil {
+		t.Fatalf("MkdirAll below a deep prefix: %v", err)
+	}
+
+	// Create a tree deeper than the limit below the wrapper.
+	if err := mfs.MkdirAll("/deep/1/2/3/4", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Walk("/deep", func(string, os.FileInfo, error) error { return nil }); !errors.Is(err, ptfs.ErrMaxDepthExceeded) {
+		t.Fatalf("Walk = %v, want %v", err, ptfs.ErrMaxDepthExceeded)
+	}
+	if err := fs.CopyTree("/deep", "/copy"); !errors.Is(err, ptfs.ErrMaxDepthExceeded) {
+		t.Fatalf("CopyTree = %v, want %v", err, ptfs.ErrMaxDepthExceeded)
+	}
+	// Entries that a depth first removal would reach before the deep one
+	// are kept too.
+	writeFile(t, mfs, "/deep/0", "x")
+	writeFile(t, mfs, "/deep/1/0", "x")
+	if err := fs.RemoveAll("/deep"); !errors.Is(err, ptfs.ErrMaxDepthExceeded) {
+		t.Fatalf("RemoveAll = %v, want %v", err, ptfs.ErrMaxDepthExceeded)
+	}
+	for _, name := range []string{"/deep/0", "/deep/1/0", "/deep/1/2/3/4"} {
+		if _, err := fs.Stat(name); err != nil {
+			t.Fatalf("RemoveAll removed part of a tree beyond the limit: %v", err)
+		}
+	}
+
+	// A shallow tree below a deep prefix is within the limit.
+	writeFile(t, mfs, "/deep/1/2/3/4/file", "x")
+	if err := fs.RemoveAll("/deep/1/2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RemoveAll("/deep/1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RemoveAll("/a/b/c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RemoveAll("/a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("/a"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Stat(/a) after RemoveAll = %v, want not exist", err)
+	}
+	if err := fs.RemoveAll("/missing"); err != nil {
+		t.Fatalf("RemoveAll(/missing) = %v", err)
+	}
+}
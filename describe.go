@@ -0,0 +1,205 @@
+package ptfs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// describer is implemented by the adapters this package places below a
+// FileSystem, such as the one of NewDirQuotaFS, so that Describe can report
+// their configuration and continue with the filesystem they wrap, which is
+// nil if there is none.
+type describer interface {
+	describe() (desc string, inner absfs.FileSystem)
+}
+
+// Describe returns a multi-line description of the stack of filesystems
+// below f, starting with f itself, for debugging how composed wrappers
+// behave. Each line describes one layer, indented by its depth, as its name
+// or type followed by a summary of its configuration, such as the options
+// given to a FileSystem or the quotas of NewDirQuotaFS. The innermost
+// filesystem is described by its type.
+func (f *FileSystem) Describe() string {
+	var b strings.Builder
+	var fs absfs.FileSystem = f
+	for depth := 0; fs != nil; depth++ {
+		var desc string
+		switch pfs := fs.(type) {
+		case *FileSystem:
+			desc, fs = label(pfs.name, pfs)+": "+pfs.describe(), pfs.fs
+		case *SymlinkFileSystem:
+			desc, fs = label(pfs.fs.name, pfs)+": "+pfs.fs.describe(), pfs.sfs
+		case describer:
+			var summary string
+			summary, fs = pfs.describe()
+			desc = label("", pfs.(absfs.FileSystem)) + ": " + summary
+		default:
+			desc, fs = label("", fs), nil
+		}
+		fmt.Fprintf(&b, "%s%s\n", strings.Repeat("  ", depth), desc)
+	}
+	return b.String()
+}
+
+// describe summarizes the options of f.
+func (f *FileSystem) describe() string {
+	var opts []string
+	add := func(format string, args ...any) {
+		opts = append(opts, fmt.Sprintf(format, args...))
+	}
+	if f.eol != 0 {
+		add("eol %d", f.eol)
+	}
+	if f.stacks {
+		add("handle stacks")
+	}
+	if f.allow != nil {
+		add("allowlist %s", f.allow.describe())
+	}
+	if f.idempotentRemove {
+		add("idempotent remove")
+	}
+	if f.writeLock {
+		add("per-path write lock")
+	}
+	if f.retries > 0 {
+		add("retries %d every %v", f.retries, f.retryDelay)
+	}
+	if f.fixedTime != nil {
+		add("fixed mod time %v", *f.fixedTime)
+	}
+	if len(f.validNames) > 0 {
+		add("name checks %d", len(f.validNames))
+	}
+	if f.errs != nil {
+		add("last errors")
+	}
+	if len(f.rewrites) > 0 {
+		add("rewrites %d", len(f.rewrites))
+	}
+	if f.maxDepth > 0 {
+		add("max depth %d", f.maxDepth)
+	}
+	if f.ioDeadline > 0 {
+		add("io deadline %v", f.ioDeadline)
+	}
+	if f.sparseBlock > 0 {
+		add("sparse copy %d", f.sparseBlock)
+	}
+	if f.identity != nil {
+		add("access identity %d:%d", f.identity.uid, f.identity.gid)
+	}
+	if f.latency > 0 {
+		add("latency %v", f.latency)
+	}
+	if f.bandwidth > 0 {
+		add("bandwidth %d B/s", f.bandwidth)
+	}
+	if f.metrics != nil {
+		add("metrics")
+	}
+	if f.accesses != nil {
+		add("access tracking")
+	}
+	if f.canonicalMode {
+		add("canonical mode")
+	}
+	if f.syncEvery > 0 {
+		add("periodic sync %d", f.syncEvery)
+	}
+	if f.freezeMode == FreezeError {
+		add("freeze error")
+	}
+	if f.strictMmap {
+		add("strict mmap")
+	}
+	if f.verifyWrites {
+		add("verify writes")
+	}
+	if f.aclChmod {
+		add("acl chmod")
+	}
+	if f.binarySample > 0 {
+		add("binary sample %d", f.binarySample)
+	}
+	if f.identityACL != nil {
+		add("identity acl %d", len(f.identityACL))
+	}
+	if f.syncCompare != nil {
+		add("custom sync compare")
+	}
+	if f.writeTokens != nil {
+		add("write rate %g/s burst %g", f.writeTokens.rate, f.writeTokens.burst)
+	}
+	if f.onEvent != nil {
+		add("events")
+	}
+	if f.eventWindow > 0 {
+		add("debounced events %v", f.eventWindow)
+	}
+	if f.createParents {
+		add("create parents %v", f.parentPerm)
+	}
+	if f.modTimeLoc != nil {
+		add("mod time location %v", f.modTimeLoc)
+	}
+	if f.chtimesSkipMissing {
+		add("chtimes skip missing")
+	}
+	if f.categorizeErrors {
+		add("categorized errors")
+	}
+	if f.pageCache != nil {
+		add("page cache %d", f.pageCache.max)
+	}
+	if len(opts) == 0 {
+		return "pass through"
+	}
+	return strings.Join(opts, ", ")
+}
+
+// describe formats the list as its sorted operations and their prefixes.
+func (l Allowlist) describe() string {
+	ops := make([]string, 0, len(l))
+	for op, prefixes := range l {
+		ops = append(ops, op+":"+strings.Join(prefixes, "|"))
+	}
+	sort.Strings(ops)
+	return "[" + strings.Join(ops, " ") + "]"
+}
+
+func (q *quotaFS) describe() (string, absfs.FileSystem) {
+	dirs := make([]string, 0, len(q.limits))
+	for dir, limit := range q.limits {
+		dirs = append(dirs, fmt.Sprintf("%s=%d", dir, limit))
+	}
+	sort.Strings(dirs)
+	return "dir quotas " + strings.Join(dirs, " "), q.FileSystem
+}
+
+func (f *promotionFS) describe() (string, absfs.FileSystem) {
+	return "promotes to " + label("", f.fast), f.FileSystem
+}
+
+func (r *routerFS) describe() (string, absfs.FileSystem) {
+	mounts := make([]string, len(r.mounts))
+	for i, m := range r.mounts {
+		mounts[i] = m.prefix + "=" + label("", DeepUnwrapFS(m.fs))
+	}
+	sort.Strings(mounts)
+	return "mounts " + strings.Join(mounts, " "), r.root
+}
+
+func (f *prefetchFS) describe() (string, absfs.FileSystem) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fmt.Sprintf("prefetch %d/%d cached, %d/%d sources, max %d bytes",
+		len(f.cache), prefetchCached, len(f.transitions), prefetchSources, prefetchMaxSize), f.FileSystem
+}
+
+func (f *ioFS) describe() (string, absfs.FileSystem) {
+	return fmt.Sprintf("read-only %T", f.fsys), nil
+}
@@ -0,0 +1,60 @@
+package ptfs_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestDescribe(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := ptfs.NewFS(mfs, ptfs.WithMetrics(ptfs.NewMetrics()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	readonly, err := ptfs.NewFS(metrics.WithName("metrics"), ptfs.WithAllowlist(ptfs.Allowlist{
+		"Open": {"/"},
+		"Stat": {"/"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, err := ptfs.NewRewriteFS(readonly.WithName("readonly"), func(op, name string) (string, error) {
+		return path.Join("/tenant", name), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix.WithName("prefix")
+
+	got := prefix.Describe()
+	want := "prefix: rewrites 1\n" +
+		"  readonly: allowlist [Open:/ Stat:/]\n" +
+		"    metrics: metrics\n" +
+		"      *memfs.FileSystem\n"
+	if got != want {
+		t.Errorf("Describe() =\n%s\nwant\n%s", got, want)
+	}
+
+	quota, err := ptfs.NewDirQuotaFS(mfs, map[string]int64{"/home": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := quota.Describe(); !strings.Contains(got, "dir quotas /home=100\n") || !strings.HasSuffix(got, "*memfs.FileSystem\n") {
+		t.Errorf("Describe() of a quota filesystem =\n%s", got)
+	}
+
+	prefetch, err := ptfs.NewPrefetchFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := prefetch.Describe(); !strings.Contains(got, "prefetch 0/64 cached, 0/1024 sources, max 1048576 bytes\n") || !strings.HasSuffix(got, "*memfs.FileSystem\n") {
+		t.Errorf("Describe() of a prefetch filesystem =\n%s", got)
+	}
+}
@@ -0,0 +1,94 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// FileMeta holds the metadata of a file recorded by SnapshotMeta.
+type FileMeta struct {
+	Size    int64
+	ModTime time.Time
+	Mode    os.FileMode
+}
+
+// SnapshotMeta returns the metadata of every file and directory in the tree
+// rooted at root, including root, keyed by path as visited by Walk.
+func (f *FileSystem) SnapshotMeta(root string) (map[string]FileMeta, error) {
+	snap := make(map[string]FileMeta)
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		snap[name] = FileMeta{Size: info.Size(), ModTime: info.ModTime(), Mode: info.Mode()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// DiffSince compares the current tree with a snapshot returned by
+// SnapshotMeta, and returns the sorted paths that were added, modified and
+// removed since. The tree compared is rooted at the root of the snapshot,
+// which is its shortest path. A file is modified if its size, modification
+// time or mode changed. Directories are only compared by mode, since their
+// size and modification time change whenever an entry is added or removed,
+// which is reported for the entry itself.
+func (f *FileSystem) DiffSince(prior map[string]FileMeta) (added, modified, removed []string, err error) {
+	root := ""
+	for name := range prior {
+		if root == "" || len(name) < len(root) {
+			root = name
+		}
+	}
+	if root == "" {
+		return nil, nil, nil, nil
+	}
+
+	seen := make(map[string]bool, len(prior))
+	err = f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if name == root && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		old, ok := prior[name]
+		if !ok {
+			added = append(added, name)
+			return nil
+		}
+		seen[name] = true
+		if metaChanged(old, info) {
+			modified = append(modified, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for name := range prior {
+		if !seen[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(modified)
+	sort.Strings(removed)
+	return added, modified, removed, nil
+}
+
+// metaChanged reports whether info differs from the recorded metadata.
+func metaChanged(old FileMeta, info os.FileInfo) bool {
+	if old.Mode != info.Mode() {
+		return true
+	}
+	if info.IsDir() {
+		return false
+	}
+	return old.Size != info.Size() || !old.ModTime.Equal(info.ModTime())
+}
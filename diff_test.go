@@ -0,0 +1,72 @@
+package ptfs_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestDiffSince(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/root/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/root/keep", "keep")
+	writeFile(t, fs, "/root/edit", "edit")
+	writeFile(t, fs, "/root/sub/gone", "gone")
+	writeFile(t, fs, "/outside", "outside")
+
+	prior, err := fs.SnapshotMeta("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prior) != 5 {
+		t.Fatalf("snapshot has %d entries, want 5: %v", len(prior), prior)
+	}
+
+	writeFile(t, fs, "/root/edit", "edited")
+	later := time.Now().Add(time.Hour)
+	if err := fs.Chtimes("/root/edit", later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("/root/sub/gone"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/root/sub/new", "new")
+	writeFile(t, fs, "/outside2", "outside")
+
+	added, modified, removed, err := fs.DiffSince(prior)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/root/sub/new"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []string{"/root/edit"}; !reflect.DeepEqual(modified, want) {
+		t.Errorf("modified = %v, want %v", modified, want)
+	}
+	if want := []string{"/root/sub/gone"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+
+	if err := fs.RemoveAll("/root"); err != nil {
+		t.Fatal(err)
+	}
+	added, modified, removed, err = fs.DiffSince(prior)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 0 || len(modified) != 0 || len(removed) != 5 {
+		t.Errorf("after removing root: added %v, modified %v, removed %v", added, modified, removed)
+	}
+}
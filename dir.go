@@ -0,0 +1,115 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"syscall"
+
+	"github.com/absfs/absfs"
+)
+
+// EnsureDir makes sure name is a directory with permissions mode, creating it
+// and any missing parents as MkdirAll does. Unlike MkdirAll, an existing
+// directory is changed to mode, and every directory created is set to mode
+// regardless of any umask applied by the underlying filesystem. If name
+// exists but is not a directory, EnsureDir returns a *os.PathError wrapping
+// syscall.ENOTDIR.
+func (f *FileSystem) EnsureDir(name string, mode os.FileMode) error {
+	var created []string
+	for dir := path.Clean(name); ; dir = path.Dir(dir) {
+		info, err := f.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return &os.PathError{Op: "ensuredir", Path: dir, Err: syscall.ENOTDIR}
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		created = append(created, dir)
+		if parent := path.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	if len(created) > 0 {
+		if err := f.MkdirAll(name, mode); err != nil {
+			return err
+		}
+	} else {
+		created = []string{name}
+	}
+	for _, dir := range created {
+		if err := f.Chmod(dir, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadDirFiltered returns the entries of the named directory for which
+// include returns true, sorted by name. The entries "." and ".." are never
+// returned.
+func (f *FileSystem) ReadDirFiltered(name string, include func(os.FileInfo) bool) ([]os.FileInfo, error) {
+	infos, err := f.readDir(name)
+	if err != nil {
+		return nil, err
+	}
+	list := infos[:0]
+	for _, info := range infos {
+		if include(info) {
+			list = append(list, info)
+		}
+	}
+	return list, nil
+}
+
+// OnlyDirs is a ReadDirFiltered predicate including only directories.
+func OnlyDirs(info os.FileInfo) bool {
+	return info.IsDir()
+}
+
+// OnlyFiles is a ReadDirFiltered predicate including only regular files.
+func OnlyFiles(info os.FileInfo) bool {
+	return info.Mode().IsRegular()
+}
+
+// EmptyDirs walks the tree rooted at root and returns the sorted paths of
+// the directories in it, including root, that have no entries. A directory
+// containing only empty directories is not itself empty.
+func (f *FileSystem) EmptyDirs(root string) ([]string, error) {
+	var dirs []string
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		infos, err := f.readDir(name)
+		if err != nil {
+			return err
+		}
+		if len(infos) == 0 {
+			dirs = append(dirs, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// OpenParent opens the directory containing name, which need not exist
+// itself. It fails if the parent does not exist, and returns a
+// *os.PathError wrapping syscall.ENOTDIR if it is not a directory.
+//
+// A rename or file creation is only durable once the directory holding the
+// new entry has been synced, so a durable replace writes and syncs a
+// temporary file, renames it over name, and then syncs the directory opened
+// by OpenParent, on backends whose directories support Sync.
+func (f *FileSystem) OpenParent(name string) (absfs.File, error) {
+	return f.OpenFile(f.Dir(name), os.O_RDONLY|O_DIRECTORY, 0)
+}
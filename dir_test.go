@@ -0,0 +1,154 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestEnsureDir(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Mkdir("/existing", 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.EnsureDir("/existing", 0755); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, fs, "/existing", 0755)
+
+	if err := fs.EnsureDir("/a/b/c", 0711); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/a", "/a/b", "/a/b/c"} {
+		assertMode(t, fs, dir, 0711)
+	}
+
+	f, err := fs.Create("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := fs.EnsureDir("/file", 0755); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("EnsureDir on a file: error = %v, want ENOTDIR", err)
+	}
+}
+
+func TestReadDirFiltered(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/mixed/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/mixed/b.txt", "b")
+	writeFile(t, fs, "/mixed/a.txt", "a")
+
+	for _, test := range []struct {
+		name    string
+		include func(os.FileInfo) bool
+		want    []string
+	}{
+		{"OnlyFiles", ptfs.OnlyFiles, []string{"a.txt", "b.txt"}},
+		{"OnlyDirs", ptfs.OnlyDirs, []string{"sub"}},
+	} {
+		infos, err := fs.ReadDirFiltered("/mixed", test.include)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name())
+		}
+		if !reflect.DeepEqual(names, test.want) {
+			t.Errorf("%s: entries = %q, want %q", test.name, names, test.want)
+		}
+	}
+	if n := len(fs.OpenHandles()); n != 0 {
+		t.Errorf("open handles = %d, want 0", n)
+	}
+}
+
+func TestEmptyDirs(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/root/empty", "/root/outer/inner", "/root/full"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, fs, "/root/full/file", "data")
+
+	dirs, err := fs.EmptyDirs("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/root/empty", "/root/outer/inner"}; !reflect.DeepEqual(dirs, want) {
+		t.Fatalf("EmptyDirs = %v, want %v", dirs, want)
+	}
+}
+
+func TestOpenParent(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/a/b", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/a/b/c.txt", "c")
+
+	dir, err := fs.OpenParent("/a/b/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dir.Close()
+	if got := dir.Name(); got != "/a/b" {
+		t.Errorf("Name() = %q, want %q", got, "/a/b")
+	}
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, name := range names {
+		found = found || name == "c.txt"
+	}
+	if !found {
+		t.Errorf("Readdirnames = %v, want c.txt", names)
+	}
+
+	if _, err := fs.OpenParent("/missing/c.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("OpenParent with a missing parent = %v, want ErrNotExist", err)
+	}
+	if _, err := fs.OpenParent("/a/b/c.txt/d"); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("OpenParent with a file parent = %v, want ENOTDIR", err)
+	}
+}
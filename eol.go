@@ -0,0 +1,250 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// EOLMode selects the line ending translations applied by an EOL filesystem.
+// Modes may be combined, although combining both read or both write
+// translations is not meaningful.
+type EOLMode int
+
+const (
+	// EOLReadLF translates CRLF to LF when reading.
+	EOLReadLF EOLMode = 1 << iota
+
+	// EOLReadCRLF translates LF to CRLF when reading.
+	EOLReadCRLF
+
+	// EOLWriteLF translates CRLF to LF when writing.
+	EOLWriteLF
+
+	// EOLWriteCRLF translates LF to CRLF when writing.
+	EOLWriteCRLF
+
+	// EOLWindows stores CRLF line endings while presenting LF line endings
+	// to the caller.
+	EOLWindows = EOLReadLF | EOLWriteCRLF
+
+	// EOLUnix stores LF line endings while presenting CRLF line endings to
+	// the caller.
+	EOLUnix = EOLReadCRLF | EOLWriteLF
+)
+
+// NewEOLFS returns a FileSystem that translates line endings of files read or
+// written through it according to mode. By default every file is translated,
+// use WithEOLMatch to restrict translation to text files.
+//
+// Because translation changes byte counts, Seek, ReadAt and WriteAt on
+// translated files return ErrNotSupported, and only streaming Read and Write
+// are supported. Sizes reported by Stat are the sizes stored by the underlying
+// filesystem, not the number of bytes a caller will read.
+func NewEOLFS(fs absfs.FileSystem, mode EOLMode, opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithEOL(mode)}, opts...)...)
+}
+
+// WithEOL enables line ending translation according to mode.
+func WithEOL(mode EOLMode) Option {
+	return func(f *FileSystem) error {
+		f.eol = mode
+		return nil
+	}
+}
+
+// WithEOLMatch restricts line ending translation to files for which match
+// returns true.
+func WithEOLMatch(match func(name string) bool) Option {
+	return func(f *FileSystem) error {
+		f.eolMatch = match
+		return nil
+	}
+}
+
+// MatchExt returns a predicate reporting whether a file name has one of the
+// given extensions. Extensions include the leading dot and are compared
+// case insensitively.
+func MatchExt(exts ...string) func(name string) bool {
+	return func(name string) bool {
+		ext := path.Ext(name)
+		for _, e := range exts {
+			if strings.EqualFold(ext, e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type eolFile struct {
+	absfs.File
+	mode EOLMode
+
+	buf     []byte // translated bytes not yet returned by Read
+	readCR  bool   // a '\r' was read but not yet returned
+	rawCR   bool   // the last byte read was '\r'
+	writeCR bool   // a '\r' was written but not yet passed on
+	lastCR  bool   // the last byte written was '\r'
+}
+
+func newEOLFile(f absfs.File, mode EOLMode) *eolFile {
+	return &eolFile{File: f, mode: mode}
+}
+
+func (f *eolFile) Read(p []byte) (int, error) {
+	if f.mode&(EOLReadLF|EOLReadCRLF) == 0 {
+		return f.File.Read(p)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	raw := make([]byte, len(p))
+	for len(f.buf) == 0 {
+		n, err := f.File.Read(raw)
+		f.buf = f.translateRead(f.buf, raw[:n])
+		if err != nil {
+			if f.readCR {
+				f.buf = append(f.buf, '\r')
+				f.readCR = false
+			}
+			if len(f.buf) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *eolFile) translateRead(dst, raw []byte) []byte {
+	for _, c := range raw {
+		if f.mode&EOLReadLF != 0 {
+			if f.readCR {
+				f.readCR = false
+				if c != '\n' {
+					dst = append(dst, '\r')
+				}
+			}
+			if c == '\r' {
+				f.readCR = true
+				continue
+			}
+			dst = append(dst, c)
+			continue
+		}
+		if c == '\n' && !f.rawCR {
+			dst = append(dst, '\r')
+		}
+		f.rawCR = c == '\r'
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+func (f *eolFile) Write(p []byte) (int, error) {
+	if f.mode&(EOLWriteLF|EOLWriteCRLF) == 0 {
+		return f.File.Write(p)
+	}
+	out := make([]byte, 0, len(p)+len(p)/8+1)
+	held, lastCR := f.writeCR, f.lastCR
+	for _, c := range p {
+		if f.mode&EOLWriteLF != 0 {
+			if f.writeCR {
+				f.writeCR = false
+				if c != '\n' {
+					out = append(out, '\r')
+				}
+			}
+			if c == '\r' {
+				f.writeCR = true
+				continue
+			}
+			out = append(out, c)
+			continue
+		}
+		if c == '\n' && !f.lastCR {
+			out = append(out, '\r')
+		}
+		f.lastCR = c == '\r'
+		out = append(out, c)
+	}
+	n, err := f.File.Write(out)
+	if err != nil {
+		// The translated length differs from len(p), so report how much of
+		// p was written in terms of the caller's bytes, and keep the state
+		// matching what was written.
+		var written int
+		written, f.writeCR, f.lastCR = eolWritten(p, n, f.mode, held, lastCR)
+		return written, err
+	}
+	return len(p), nil
+}
+
+// eolWritten returns the number of bytes of p that were fully written when n
+// translated bytes reached the underlying file, given the writeCR and lastCR
+// state carried over from the previous Write, along with the state after
+// those bytes.
+func eolWritten(p []byte, n int, mode EOLMode, writeCR, lastCR bool) (int, bool, bool) {
+	out := 0
+	for i, c := range p {
+		if mode&EOLWriteLF != 0 {
+			if writeCR && c != '\n' {
+				// The held '\r' is passed on first.
+				if out == n {
+					return i, writeCR, lastCR
+				}
+				out++
+				writeCR = false
+			}
+			if c == '\r' {
+				writeCR = true
+				continue
+			}
+			writeCR = false
+		} else if c == '\n' && !lastCR {
+			if out == n {
+				return i, writeCR, lastCR
+			}
+			out++
+			lastCR = true
+		}
+		if out == n {
+			return i, writeCR, lastCR
+		}
+		out++
+		lastCR = c == '\r'
+	}
+	return len(p), writeCR, lastCR
+}
+
+func (f *eolFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *eolFile) Close() error {
+	if f.writeCR {
+		f.writeCR = false
+		if _, err := f.File.Write([]byte{'\r'}); err != nil {
+			f.File.Close()
+			return err
+		}
+	}
+	return f.File.Close()
+}
+
+func (f *eolFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, &os.PathError{Op: "seek", Path: f.Name(), Err: ErrNotSupported}
+}
+
+func (f *eolFile) ReadAt(b []byte, off int64) (int, error) {
+	return 0, &os.PathError{Op: "readat", Path: f.Name(), Err: ErrNotSupported}
+}
+
+func (f *eolFile) WriteAt(b []byte, off int64) (int, error) {
+	return 0, &os.PathError{Op: "writeat", Path: f.Name(), Err: ErrNotSupported}
+}
@@ -0,0 +1,139 @@
+package ptfs_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestEOLFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewEOLFS(mfs, ptfs.EOLWindows, ptfs.WithEOLMatch(ptfs.MatchExt(".txt")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Create("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("one\ntwo\r\nthree\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); !errors.Is(err, ptfs.ErrNotSupported) {
+		t.Errorf("Seek error = %v, want ErrNotSupported", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := readAll(t, mfs, "/a.txt")
+	if want := "one\r\ntwo\r\nthree\r\n"; raw != want {
+		t.Errorf("stored %q, want %q", raw, want)
+	}
+	if got, want := readAll(t, fs, "/a.txt"), "one\ntwo\nthree\n"; got != want {
+		t.Errorf("read %q, want %q", got, want)
+	}
+
+	// Files that do not match the predicate pass through untouched.
+	f, err = fs.Create("/a.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("x\ny"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got := readAll(t, mfs, "/a.bin"); got != "x\ny" {
+		t.Errorf("stored %q, want %q", got, "x\ny")
+	}
+}
+
+// budgetFS creates files that accept at most budget more bytes, failing
+// writes beyond that with io.ErrShortWrite.
+type budgetFS struct {
+	absfs.FileSystem
+	budget *int
+}
+
+func (fs budgetFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return budgetFile{f, fs.budget}, nil
+}
+
+type budgetFile struct {
+	absfs.File
+	budget *int
+}
+
+func (f budgetFile) Write(p []byte) (int, error) {
+	if len(p) <= *f.budget {
+		*f.budget -= len(p)
+		return f.File.Write(p)
+	}
+	n, _ := f.File.Write(p[:*f.budget])
+	*f.budget = 0
+	return n, io.ErrShortWrite
+}
+
+func TestEOLShortWrite(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name   string
+		mode   ptfs.EOLMode
+		budget int    // bytes accepted before the second write fails
+		want   int    // bytes of the second write reported written
+		stored string // after retrying the rest
+	}{
+		// "\n" completes the "\r\n" split across the writes, so it is
+		// stored as is, and fits the budget.
+		{"crlf", ptfs.EOLWriteCRLF, 3, 1, "a\r\nb"},
+		// The '\r' held from the first write fits, "b" does not.
+		{"lf", ptfs.EOLWriteLF, 2, 0, "a\rb"},
+	} {
+		budget := test.budget
+		fs, err := ptfs.NewEOLFS(budgetFS{mfs, &budget}, test.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "/" + test.name
+		f, err := fs.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := f.WriteString("a\r"); n != 2 || err != nil {
+			t.Fatalf("%s: first write = %d, %v", test.name, n, err)
+		}
+		rest := "\nb"
+		if test.mode == ptfs.EOLWriteLF {
+			rest = "b"
+		}
+		n, err := f.WriteString(rest)
+		if n != test.want || !errors.Is(err, io.ErrShortWrite) {
+			t.Errorf("%s: short write = %d, %v, want %d, ErrShortWrite", test.name, n, err, test.want)
+		}
+		budget = 100
+		if _, err := f.WriteString(rest[n:]); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := readAll(t, mfs, name); got != test.stored {
+			t.Errorf("%s: stored %q, want %q", test.name, got, test.stored)
+		}
+	}
+}
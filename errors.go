@@ -0,0 +1,75 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// ErrNotSupported is returned when an operation is not supported by a wrapper
+// or by the underlying filesystem. Errors for operations on a path wrap it in
+// a *os.PathError.
+var ErrNotSupported = errors.New("operation not supported")
+
+// Error categories reported with WithCategorizedErrors, along with
+// ErrNotSupported.
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrPermission = errors.New("permission denied")
+	ErrExists     = errors.New("file already exists")
+	ErrIO         = errors.New("i/o error")
+)
+
+// isNotSupported reports whether err indicates that an operation is not
+// supported, rather than that it failed.
+func isNotSupported(err error) bool {
+	return errors.Is(err, ErrNotSupported) ||
+		errors.Is(err, errors.ErrUnsupported) ||
+		errors.Is(err, syscall.ENOTSUP)
+}
+
+// WithCategorizedErrors makes every error returned by an operation of the
+// FileSystem also match one of ErrNotFound, ErrPermission, ErrExists,
+// ErrNotSupported or ErrIO with errors.Is, so callers can handle errors from
+// different backends alike. Errors are categorized by testing them against
+// os.ErrNotExist, os.ErrPermission and os.ErrExist, and ErrIO is used for
+// errors matching none of them. The original error is still wrapped, and
+// its message returned unchanged, so errors.Is and errors.As reach it as
+// before. Only errors from FileSystem methods are categorized; errors from
+// the methods of open files, such as Read, Write or Readdir, are returned as
+// the backend reports them.
+func WithCategorizedErrors() Option {
+	return func(f *FileSystem) error {
+		f.categorizeErrors = true
+		return nil
+	}
+}
+
+// categorize returns err wrapped with its category, as set out by
+// WithCategorizedErrors.
+func categorize(err error) error {
+	var c *categorizedError
+	if err == nil || errors.As(err, &c) {
+		return err
+	}
+	category := ErrIO
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		category = ErrNotFound
+	case errors.Is(err, os.ErrPermission):
+		category = ErrPermission
+	case errors.Is(err, os.ErrExist):
+		category = ErrExists
+	case isNotSupported(err):
+		category = ErrNotSupported
+	}
+	return &categorizedError{category, err}
+}
+
+type categorizedError struct {
+	category error
+	err      error
+}
+
+func (e *categorizedError) Error() string   { return e.err.Error() }
+func (e *categorizedError) Unwrap() []error { return []error{e.err, e.category} }
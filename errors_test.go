@@ -0,0 +1,71 @@
+package ptfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// errNoObject is a backend specific error for a missing file.
+type errNoObject struct{ key string }
+
+func (e *errNoObject) Error() string        { return "no such object: " + e.key }
+func (e *errNoObject) Is(target error) bool { return target == fs.ErrNotExist }
+
+// objectFS reports missing files with errNoObject.
+type objectFS struct {
+	absfs.FileSystem
+}
+
+func (o objectFS) Stat(name string) (os.FileInfo, error) {
+	info, err := o.FileSystem.Stat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &errNoObject{name}
+	}
+	return info, err
+}
+
+func TestCategorizedErrors(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(objectFS{mfs}, ptfs.WithCategorizedErrors())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fs.Stat("/missing")
+	if !errors.Is(err, ptfs.ErrNotFound) {
+		t.Errorf("Stat error = %v, want ErrNotFound", err)
+	}
+	var noObject *errNoObject
+	if !errors.As(err, &noObject) || noObject.key != "/missing" {
+		t.Errorf("Stat error = %v, want it to wrap *errNoObject", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat error = %v, want it to still match os.ErrNotExist", err)
+	}
+	if got, want := err.Error(), "no such object: /missing"; got != want {
+		t.Errorf("Stat error message = %q, want %q", got, want)
+	}
+
+	if err := fs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = fs.Mkdir("/dir", 0755)
+	if !errors.Is(err, ptfs.ErrExists) || errors.Is(err, ptfs.ErrNotFound) {
+		t.Errorf("Mkdir error = %v, want ErrExists only", err)
+	}
+
+	// Categories are not confused with the wrapped error.
+	if errors.Is(err, syscall.ENOTSUP) || errors.Is(err, ptfs.ErrIO) {
+		t.Errorf("Mkdir error = %v matches other categories", err)
+	}
+}
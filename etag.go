@@ -0,0 +1,74 @@
+package ptfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path"
+	"sync"
+	"time"
+)
+
+// ETag returns a strong HTTP entity tag for the named file, derived from a
+// hash of its content and quoted for use in an ETag header. Tags are cached by
+// path, modification time and size, so the content is only hashed again after
+// the file changes.
+func (f *FileSystem) ETag(name string) (string, error) {
+	info, err := f.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	key := etagKey{path.Clean(name), info.ModTime(), info.Size()}
+	if tag, ok := f.etags.get(key); ok {
+		return tag, nil
+	}
+
+	file, err := f.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	tag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	f.etags.put(key, tag)
+	return tag, nil
+}
+
+type etagKey struct {
+	name    string
+	modTime time.Time
+	size    int64
+}
+
+// etagCache holds the most recent tag computed for each path.
+type etagCache struct {
+	mu   sync.Mutex
+	tags map[string]etagEntry
+}
+
+type etagEntry struct {
+	key etagKey
+	tag string
+}
+
+func (c *etagCache) get(key etagKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tags[key.name]
+	if !ok || !e.key.modTime.Equal(key.modTime) || e.key.size != key.size {
+		return "", false
+	}
+	return e.tag, true
+}
+
+func (c *etagCache) put(key etagKey, tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tags == nil {
+		c.tags = make(map[string]etagEntry)
+	}
+	c.tags[key.name] = etagEntry{key, tag}
+}
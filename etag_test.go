@@ -0,0 +1,45 @@
+package ptfs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestETag(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/index.html", "<html>")
+
+	tag, err := fs.ETag("/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		t.Errorf("ETag %s is not quoted", tag)
+	}
+	again, err := fs.ETag("/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != tag {
+		t.Errorf("ETag changed from %s to %s for an unchanged file", tag, again)
+	}
+
+	writeFile(t, fs, "/index.html", "<html></html>")
+	changed, err := fs.ETag("/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == tag {
+		t.Errorf("ETag %s did not change after the file was modified", tag)
+	}
+}
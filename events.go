@@ -0,0 +1,121 @@
+package ptfs
+
+import (
+	"sync"
+	"time"
+)
+
+// An Event describes a change made through a FileSystem.
+type Event struct {
+	// Op is the name of the FileSystem method that made the change, such as
+	// "Mkdir", "Remove" or "Rename", or "Write" for a file opened for writing
+	// that was closed.
+	Op string
+
+	// Path is the path changed, as given by the caller. For Rename it is the
+	// new path.
+	Path string
+
+	// OldPath is the old path of a Rename, and empty otherwise.
+	OldPath string
+}
+
+// WithEvents calls fn with an Event after each successful change made
+// through the FileSystem: Mkdir, MkdirAll, Remove, RemoveAll, Rename, Chmod,
+// Chtimes, Chown, Truncate and Symlink, and the closing of a file opened for
+// writing, which is reported as "Write" once the content has been written
+// and the file closed without error.
+// fn is called synchronously by the goroutine making the change, unless
+// events are debounced with WithDebouncedEvents.
+func WithEvents(fn func(Event)) Option {
+	return func(f *FileSystem) error {
+		f.onEvent = fn
+		return nil
+	}
+}
+
+// WithDebouncedEvents coalesces the events given to the function set with
+// WithEvents, so that a path changed repeatedly produces one event, the
+// latest, once window has passed without a further change to it. This
+// reduces the work of consumers of rapidly rewritten files, at the cost of
+// delaying every event by at least window. Debounced events are delivered
+// from their own goroutines, and events for different paths may be
+// delivered in any order. Call FlushEvents before shutting down so that no
+// pending event is lost.
+func WithDebouncedEvents(window time.Duration) Option {
+	return func(f *FileSystem) error {
+		f.eventWindow = window
+		return nil
+	}
+}
+
+// FlushEvents delivers every event held back by WithDebouncedEvents at once,
+// without waiting for its window to pass.
+func (f *FileSystem) FlushEvents() {
+	f.debounce.flush(f.onEvent)
+}
+
+// notify emits ev if *err is nil.
+func (f *FileSystem) notify(ev Event, err *error) {
+	if f.onEvent != nil && *err == nil {
+		f.emit(ev)
+	}
+}
+
+func (f *FileSystem) emit(ev Event) {
+	if f.eventWindow > 0 {
+		f.debounce.add(ev, f.eventWindow, f.onEvent)
+		return
+	}
+	f.onEvent(ev)
+}
+
+// debouncer holds back the latest event for each path until no further event
+// for the path has been added for a window.
+type debouncer struct {
+	mu      sync.Mutex
+	seq     uint64
+	pending map[string]pendingEvent
+}
+
+type pendingEvent struct {
+	ev    Event
+	seq   uint64
+	timer *time.Timer
+}
+
+func (d *debouncer) add(ev Event, window time.Duration, fn func(Event)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p, ok := d.pending[ev.Path]; ok {
+		p.timer.Stop()
+	}
+	if d.pending == nil {
+		d.pending = make(map[string]pendingEvent)
+	}
+	d.seq++
+	seq := d.seq
+	d.pending[ev.Path] = pendingEvent{ev, seq, time.AfterFunc(window, func() {
+		d.mu.Lock()
+		p, ok := d.pending[ev.Path]
+		if !ok || p.seq != seq {
+			// Replaced by a later event, or flushed.
+			d.mu.Unlock()
+			return
+		}
+		delete(d.pending, ev.Path)
+		d.mu.Unlock()
+		fn(ev)
+	})}
+}
+
+func (d *debouncer) flush(fn func(Event)) {
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	for _, p := range pending {
+		p.timer.Stop()
+		fn(p.ev)
+	}
+}
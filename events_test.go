@@ -0,0 +1,145 @@
+package ptfs_test
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// eventLog collects events from concurrent goroutines.
+type eventLog struct {
+	mu     sync.Mutex
+	events []ptfs.Event
+}
+
+func (l *eventLog) add(ev ptfs.Event) {
+	l.mu.Lock()
+	l.events = append(l.events, ev)
+	l.mu.Unlock()
+}
+
+func (l *eventLog) get() []ptfs.Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]ptfs.Event(nil), l.events...)
+}
+
+func TestWithEvents(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log eventLog
+	fs, err := ptfs.NewFS(mfs, ptfs.WithEvents(log.add))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/dir/a", "a")
+	readAll(t, fs, "/dir/a")
+	if err := fs.Rename("/dir/a", "/dir/b"); err != nil {
+		t.Fatal(err)
+	}
+	fs.Remove("/missing")
+
+	want := []ptfs.Event{
+		{Op: "Mkdir", Path: "/dir"},
+		{Op: "Write", Path: "/dir/a"},
+		{Op: "Rename", Path: "/dir/b", OldPath: "/dir/a"},
+	}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestWithDebouncedEvents(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log eventLog
+	fs, err := ptfs.NewFS(mfs, ptfs.WithEvents(log.add), ptfs.WithDebouncedEvents(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		writeFile(t, fs, "/file", "data")
+	}
+	if got := log.get(); len(got) != 0 {
+		t.Fatalf("events delivered before the window passed: %v", got)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if got, want := log.get(), []ptfs.Event{{Op: "Write", Path: "/file"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+
+	// FlushEvents delivers pending events at once.
+	if err := fs.Chmod("/file", 0600); err != nil {
+		t.Fatal(err)
+	}
+	fs.FlushEvents()
+	if got := log.get(); len(got) != 2 || got[1].Op != "Chmod" {
+		t.Fatalf("events after FlushEvents = %v", got)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := log.get(); len(got) != 2 {
+		t.Fatalf("flushed event delivered again: %v", got)
+	}
+}
+
+// closeFailFS creates files whose Close fails after closing the file.
+type closeFailFS struct {
+	absfs.FileSystem
+}
+
+func (fs closeFailFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return closeFailFile{f}, nil
+}
+
+type closeFailFile struct {
+	absfs.File
+}
+
+var errCloseFail = errors.New("close failed")
+
+func (f closeFailFile) Close() error {
+	f.File.Close()
+	return errCloseFail
+}
+
+func TestWithEventsCloseFails(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log eventLog
+	fs, err := ptfs.NewFS(closeFailFS{mfs}, ptfs.WithEvents(log.add))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Create("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); !errors.Is(err, errCloseFail) {
+		t.Fatalf("Close = %v, want %v", err, errCloseFail)
+	}
+	if got := log.get(); len(got) != 0 {
+		t.Errorf("events after a failed Close = %v, want none", got)
+	}
+}
@@ -0,0 +1,64 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExtStats summarizes the files with one extension, as returned by
+// StatsByExtension.
+type ExtStats struct {
+	FileCount  int
+	TotalBytes int64
+}
+
+// StatsByExtension walks the tree rooted at root and returns the number and
+// total size of its regular files per extension. Extensions include the
+// leading dot and are lowercased, and files without an extension are counted
+// under "". Directories, symbolic links and other special files are skipped.
+func (f *FileSystem) StatsByExtension(root string) (map[string]ExtStats, error) {
+	stats := make(map[string]ExtStats)
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		ext := strings.ToLower(path.Ext(name))
+		s := stats[ext]
+		s.FileCount++
+		s.TotalBytes += info.Size()
+		stats[ext] = s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// InodeUsage counts the files, directories and other entries in the tree
+// rooted at root, not counting root itself, as an inode quota would. Once
+// the count exceeds limit the walk stops, and InodeUsage returns limit+1 and
+// exceeded true, so checking a huge tree against a small budget does not
+// walk all of it.
+func (f *FileSystem) InodeUsage(root string, limit int) (count int, exceeded bool, err error) {
+	err = f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root {
+			return nil
+		}
+		count++
+		if count > limit {
+			exceeded = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return count, exceeded, err
+}
@@ -0,0 +1,83 @@
+package ptfs_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestStatsByExtension(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs, err := ptfs.NewSymlinkFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(sfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/root/sub.txt", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/root/a.txt", "123")
+	writeFile(t, fs, "/root/sub.txt/b.TXT", "12345")
+	writeFile(t, fs, "/root/c.bin", "1234567")
+	writeFile(t, fs, "/root/README", "12")
+	if err := sfs.Symlink("/root/c.bin", "/root/link.bin"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fs.StatsByExtension("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]ptfs.ExtStats{
+		".txt": {FileCount: 2, TotalBytes: 8},
+		".bin": {FileCount: 1, TotalBytes: 7},
+		"":     {FileCount: 1, TotalBytes: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("StatsByExtension = %v, want %v", got, want)
+	}
+}
+
+func TestInodeUsage(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/small/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/small/a", "a")
+	writeFile(t, fs, "/small/sub/b", "b")
+	for i := 0; i < 50; i++ {
+		if err := fs.MkdirAll(fmt.Sprintf("/big/%02d", i), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, fs, fmt.Sprintf("/big/%02d/file", i), "x")
+	}
+
+	count, exceeded, err := fs.InodeUsage("/small", 10)
+	if err != nil || count != 3 || exceeded {
+		t.Errorf("InodeUsage(/small) = %d, %v, %v, want 3, false", count, exceeded, err)
+	}
+	count, exceeded, err = fs.InodeUsage("/big", 10)
+	if err != nil || count != 11 || !exceeded {
+		t.Errorf("InodeUsage(/big) = %d, %v, %v, want 11, true", count, exceeded, err)
+	}
+	count, exceeded, err = fs.InodeUsage("/big", 100)
+	if err != nil || count != 100 || exceeded {
+		t.Errorf("InodeUsage(/big, 100) = %d, %v, %v, want 100, false", count, exceeded, err)
+	}
+}
@@ -0,0 +1,35 @@
+package ptfs
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/absfs/absfs"
+)
+
+// O_DIRECTORY may be included in the flags passed to OpenFile to require that
+// the named file is a directory. Not every backend understands O_DIRECTORY,
+// so the flag is removed before the call is passed through, and the opened
+// file is checked by the wrapper instead. If it is not a directory it is
+// closed and OpenFile returns a *os.PathError wrapping syscall.ENOTDIR.
+//
+// O_DIRECTORY is specific to ptfs and is not the same value as
+// syscall.O_DIRECTORY.
+const O_DIRECTORY = 1 << 30
+
+// openFile opens name on fs, enforcing O_DIRECTORY.
+func openFile(fs absfs.Filer, name string, flag int, perm os.FileMode) (absfs.File, error) {
+	file, err := fs.OpenFile(name, flag&^O_DIRECTORY, perm)
+	if err != nil || flag&O_DIRECTORY == 0 {
+		return file, err
+	}
+	info, err := file.Stat()
+	if err == nil && !info.IsDir() {
+		err = &os.PathError{Op: "open", Path: name, Err: syscall.ENOTDIR}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,44 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestODirectory(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Create("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	_, err = fs.OpenFile("/file", os.O_RDONLY|ptfs.O_DIRECTORY, 0)
+	if !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("opening a file with O_DIRECTORY: error = %v, want ENOTDIR", err)
+	}
+	if n := len(fs.OpenHandles()); n != 0 {
+		t.Errorf("open handles = %d, want 0", n)
+	}
+
+	d, err := fs.OpenFile("/dir", os.O_RDONLY|ptfs.O_DIRECTORY, 0)
+	if err != nil {
+		t.Fatalf("opening a directory with O_DIRECTORY: %v", err)
+	}
+	d.Close()
+}
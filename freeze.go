@@ -0,0 +1,78 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrFrozen is returned, wrapped in a *os.PathError, by operations on a
+// FileSystem frozen with Freeze in FreezeError mode.
+var ErrFrozen = errors.New("filesystem is frozen")
+
+// A FreezeMode selects how operations behave while a FileSystem is frozen.
+type FreezeMode int
+
+const (
+	// FreezeBlock makes operations wait until the FileSystem is unfrozen.
+	FreezeBlock FreezeMode = iota
+
+	// FreezeError makes operations fail immediately with ErrFrozen.
+	FreezeError
+)
+
+// WithFreezeMode sets how operations behave while the FileSystem is frozen.
+// The default is FreezeBlock.
+func WithFreezeMode(mode FreezeMode) Option {
+	return func(f *FileSystem) error {
+		f.freezeMode = mode
+		return nil
+	}
+}
+
+// Freeze pauses the FileSystem, for instance during a maintenance window or
+// while a snapshot is taken. Until Unfreeze is called, every operation of
+// the FileSystem taking a path either waits or fails with ErrFrozen,
+// depending on the mode set with WithFreezeMode. Operations already in
+// progress, and I/O on files that are already open, are not affected.
+// Freezing a frozen FileSystem does nothing.
+func (f *FileSystem) Freeze() {
+	f.freeze.mu.Lock()
+	defer f.freeze.mu.Unlock()
+	if f.freeze.thawed == nil {
+		f.freeze.thawed = make(chan struct{})
+	}
+}
+
+// Unfreeze resumes a FileSystem paused by Freeze, releasing any waiting
+// operations.
+func (f *FileSystem) Unfreeze() {
+	f.freeze.mu.Lock()
+	defer f.freeze.mu.Unlock()
+	if f.freeze.thawed != nil {
+		close(f.freeze.thawed)
+		f.freeze.thawed = nil
+	}
+}
+
+type freezeGate struct {
+	mu     sync.Mutex
+	thawed chan struct{} // closed by Unfreeze, nil when not frozen
+}
+
+// checkFrozen waits until the FileSystem is not frozen, or returns an error
+// if it is frozen in FreezeError mode.
+func (f *FileSystem) checkFrozen(op, name string) error {
+	for {
+		f.freeze.mu.Lock()
+		thawed := f.freeze.thawed
+		f.freeze.mu.Unlock()
+		if thawed == nil {
+			return nil
+		}
+		if f.freezeMode == FreezeError {
+			return &os.PathError{Op: op, Path: name, Err: ErrFrozen}
+		}
+		<-thawed
+	}
+}
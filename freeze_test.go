@@ -0,0 +1,68 @@
+package ptfs_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestFreezeError(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithFreezeMode(ptfs.FreezeError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/file", "data")
+
+	fs.Freeze()
+	if _, err := fs.Open("/file"); !errors.Is(err, ptfs.ErrFrozen) {
+		t.Fatalf("Open while frozen = %v, want %v", err, ptfs.ErrFrozen)
+	}
+	fs.Unfreeze()
+	if got := readAll(t, fs, "/file"); got != "data" {
+		t.Fatalf("read after Unfreeze = %q, want %q", got, "data")
+	}
+}
+
+func TestFreezeBlock(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/file", "data")
+
+	fs.Freeze()
+	done := make(chan error)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := fs.Stat("/file")
+			done <- err
+		}()
+	}
+	select {
+	case <-done:
+		t.Fatal("Stat did not wait while frozen")
+	case <-time.After(20 * time.Millisecond):
+	}
+	fs.Unfreeze()
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Unfreeze did not release waiting operations")
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Glob returns the sorted names of all files matching pattern, or nil if
+// there are none, as filepath.Glob does over the underlying filesystem. The
+// pattern syntax is that of path.Match, with the separator of the underlying
+// filesystem in place of '/': "*", "?" and "[...]" match within a single
+// path element, and never match a separator, so "/data/*.txt" matches text
+// files in /data but not in its subdirectories. On filesystems using '\' as
+// their separator, '\' cannot be used to escape special characters. The
+// only possible returned error is path.ErrBadPattern, and I/O errors such as
+// unreadable directories are ignored.
+func (f *FileSystem) Glob(pattern string) ([]string, error) {
+	p := f.swapSlash(pattern)
+	if _, err := path.Match(p, ""); err != nil {
+		return nil, err
+	}
+	matches := f.glob(p)
+	sort.Strings(matches)
+	for i, m := range matches {
+		matches[i] = f.swapSlash(m)
+	}
+	return matches, nil
+}
+
+// glob returns the slash separated names matching the slash separated,
+// valid pattern p, in no particular order.
+func (f *FileSystem) glob(p string) []string {
+	if !hasMeta(p) {
+		if _, err := f.lstat(f.swapSlash(p)); err != nil {
+			return nil
+		}
+		return []string{p}
+	}
+
+	dir, file := path.Split(p)
+	dir = cleanGlobDir(dir)
+	dirs := []string{dir}
+	if hasMeta(dir) {
+		dirs = f.glob(dir)
+	}
+	var matches []string
+	for _, d := range dirs {
+		matches = f.globDir(matches, d, file)
+	}
+	return matches
+}
+
+// globDir appends to matches the names of the entries of the directory dir
+// matching the single element pattern elem.
+func (f *FileSystem) globDir(matches []string, dir, elem string) []string {
+	infos, err := f.readDir(f.swapSlash(dir))
+	if err != nil {
+		return matches
+	}
+	for _, info := range infos {
+		if ok, _ := path.Match(elem, info.Name()); ok {
+			matches = append(matches, path.Join(dir, info.Name()))
+		}
+	}
+	return matches
+}
+
+// cleanGlobDir prepares the directory part of a pattern split by path.Split
+// for matching, as filepath.Glob does.
+func cleanGlobDir(dir string) string {
+	switch dir {
+	case "":
+		return "."
+	case "/":
+		return dir
+	}
+	return dir[:len(dir)-1]
+}
+
+// hasMeta reports whether p contains any of the special characters
+// recognized by path.Match.
+func hasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[\`)
+}
+
+// GlobRecursive returns the sorted names of all files matching pattern, as
+// Glob does, except that a "**" path element matches any number of path
+// elements, including none, so "/src/**/*.go" matches Go files anywhere
+// under /src, including in /src itself. Elsewhere, "**" within an element
+// matches like "*". Only the directories that the pattern can match are
+// read: the walk starts at the pattern's longest leading path without
+// special characters, and descends only into directories matching the next
+// element, or into all directories below a "**". Symbolic links to
+// directories are not followed.
+func (f *FileSystem) GlobRecursive(pattern string) ([]string, error) {
+	p := f.swapSlash(pattern)
+	if _, err := path.Match(p, ""); err != nil {
+		return nil, err
+	}
+	root, rest := splitRoot(path.Clean(p))
+	var elems []string
+	if rest != "" && rest != "." {
+		elems = strings.Split(rest, "/")
+	}
+	// Start at the longest prefix without special characters.
+	i := 0
+	for i < len(elems) && !hasMeta(elems[i]) {
+		i++
+	}
+	start := root + strings.Join(elems[:i], "/")
+	if start == "" {
+		start = "."
+	}
+	info, err := f.lstat(f.swapSlash(start))
+	if err != nil {
+		return nil, nil
+	}
+
+	found := make(map[string]bool)
+	f.globRecursive(found, start, info, elems[i:])
+	matches := make([]string, 0, len(found))
+	for m := range found {
+		matches = append(matches, f.swapSlash(m))
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globRecursive adds to found the names below name, whose FileInfo is info,
+// matching elems, the remaining slash separated pattern elements.
+func (f *FileSystem) globRecursive(found map[string]bool, name string, info os.FileInfo, elems []string) {
+	if len(elems) == 0 {
+		found[name] = true
+		return
+	}
+	elem := elems[0]
+	if elem == "**" {
+		f.globRecursive(found, name, info, elems[1:])
+	}
+	if !info.IsDir() {
+		return
+	}
+	infos, err := f.readDir(f.swapSlash(name))
+	if err != nil {
+		return
+	}
+	for _, child := range infos {
+		childName := path.Join(name, child.Name())
+		if elem == "**" {
+			f.globRecursive(found, childName, child, elems)
+		} else if ok, _ := path.Match(elem, child.Name()); ok {
+			f.globRecursive(found, childName, child, elems[1:])
+		}
+	}
+}
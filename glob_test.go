@@ -0,0 +1,107 @@
+package ptfs_test
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestGlob(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/data/sub", "/logs/a", "/logs/b"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{
+		"/data/a.txt", "/data/b.txt", "/data/c.csv", "/data/sub/d.txt",
+		"/logs/a/1.log", "/logs/b/2.log", "/logs/b/x.txt",
+	} {
+		writeFile(t, fs, name, name)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/data/*.txt", []string{"/data/a.txt", "/data/b.txt"}},
+		{"/data/?.csv", []string{"/data/c.csv"}},
+		{"/data/[ab].*", []string{"/data/a.txt", "/data/b.txt"}},
+		{"/logs/*/*.log", []string{"/logs/a/1.log", "/logs/b/2.log"}},
+		{"/data/a.txt", []string{"/data/a.txt"}},
+		{"/data/missing.txt", nil},
+		{"/nowhere/*", nil},
+	}
+	for _, tt := range tests {
+		got, err := fs.Glob(tt.pattern)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Glob(%q) = %q, %v, want %q", tt.pattern, got, err, tt.want)
+		}
+	}
+
+	if _, err := fs.Glob("/data/[a"); !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("Glob with a bad pattern error = %v, want ErrBadPattern", err)
+	}
+}
+
+func TestGlobRecursive(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &openLogFS{FileSystem: mfs, opens: make(map[string]int)}
+	fs, err := ptfs.NewFS(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/src/a/b", "/src/c", "/other/deep"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{
+		"/foo.txt", "/src/main.go", "/src/a/foo.txt", "/src/a/b/lib.go",
+		"/src/c/c.go", "/src/c/c.txt", "/other/deep/foo.txt", "/other/deep/x.go",
+	} {
+		writeFile(t, fs, name, name)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/src/**/*.go", []string{"/src/a/b/lib.go", "/src/c/c.go", "/src/main.go"}},
+		{"/src/*/**/*.go", []string{"/src/a/b/lib.go", "/src/c/c.go"}},
+		{"/src/**/**/c.*", []string{"/src/c/c.go", "/src/c/c.txt"}},
+		{"/src/**", []string{"/src", "/src/a", "/src/a/b", "/src/a/b/lib.go", "/src/a/foo.txt", "/src/c", "/src/c/c.go", "/src/c/c.txt", "/src/main.go"}},
+		{"/src/*.go", []string{"/src/main.go"}},
+		{"/nowhere/**/*.go", nil},
+	}
+	for _, tt := range tests {
+		got, err := fs.GlobRecursive(tt.pattern)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GlobRecursive(%q) = %q, %v, want %q", tt.pattern, got, err, tt.want)
+		}
+	}
+
+	// A pattern below /src never reads unrelated subtrees.
+	if n := backend.count("/other") + backend.count("/other/deep"); n != 0 {
+		t.Errorf("/other opened %d times by patterns below /src", n)
+	}
+
+	got, err := fs.GlobRecursive("/**/foo.txt")
+	if want := []string{"/foo.txt", "/other/deep/foo.txt", "/src/a/foo.txt"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GlobRecursive(/**/foo.txt) = %q, %v, want %q", got, err, want)
+	}
+}
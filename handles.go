@@ -0,0 +1,98 @@
+package ptfs
+
+import (
+	"path"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HandleInfo describes a file handle opened through a FileSystem that has not
+// yet been closed.
+type HandleInfo struct {
+	Name   string    // name the file was opened with
+	Flag   int       // flags the file was opened with
+	Opened time.Time // time the file was opened
+
+	// Stack is the stack trace of the goroutine that opened the file. It is
+	// only captured when the FileSystem was created with WithHandleStacks.
+	Stack string
+}
+
+// WithHandleStacks captures a stack trace each time a file is opened, and
+// reports it in HandleInfo.Stack. Capturing stacks is expensive and intended
+// for debugging handle leaks.
+func WithHandleStacks() Option {
+	return func(f *FileSystem) error {
+		f.stacks = true
+		return nil
+	}
+}
+
+// OpenHandles returns a snapshot of the files opened through the FileSystem
+// that have not been closed, ordered by the time they were opened.
+func (f *FileSystem) OpenHandles() []HandleInfo {
+	return f.handles.list()
+}
+
+// IsOpen reports whether any file opened through the FileSystem with the
+// given name is still open. Names are compared after cleaning, relative names
+// are not resolved against the working directory.
+func (f *FileSystem) IsOpen(name string) bool {
+	return f.handles.isOpen(path.Clean(name))
+}
+
+type handleRegistry struct {
+	mu   sync.Mutex
+	next uint64
+	open map[uint64]HandleInfo
+}
+
+func (r *handleRegistry) add(name string, flag int, stack bool) uint64 {
+	info := HandleInfo{Name: name, Flag: flag, Opened: time.Now()}
+	if stack {
+		info.Stack = string(debug.Stack())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.open == nil {
+		r.open = make(map[uint64]HandleInfo)
+	}
+	r.next++
+	r.open[r.next] = info
+	return r.next
+}
+
+func (r *handleRegistry) remove(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.open, id)
+}
+
+func (r *handleRegistry) list() []HandleInfo {
+	r.mu.Lock()
+	ids := make([]uint64, 0, len(r.open))
+	for id := range r.open {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	list := make([]HandleInfo, len(ids))
+	for i, id := range ids {
+		list[i] = r.open[id]
+	}
+	r.mu.Unlock()
+	return list
+}
+
+func (r *handleRegistry) isOpen(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, info := range r.open {
+		if path.Clean(info.Name) == name {
+			return true
+		}
+	}
+	return false
+}
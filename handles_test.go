@@ -0,0 +1,82 @@
+package ptfs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestOpenHandles(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithHandleStacks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := fs.Create("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.Create("/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fs.OpenHandles()); n != 2 {
+		t.Fatalf("open handles = %d, want 2", n)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	handles := fs.OpenHandles()
+	if len(handles) != 1 {
+		t.Fatalf("open handles = %d, want 1", len(handles))
+	}
+	if handles[0].Name != "/b" {
+		t.Errorf("open handle name = %q, want %q", handles[0].Name, "/b")
+	}
+	if !strings.Contains(handles[0].Stack, "TestOpenHandles") {
+		t.Errorf("stack does not include the opening test:\n%s", handles[0].Stack)
+	}
+	b.Close()
+	if n := len(fs.OpenHandles()); n != 0 {
+		t.Errorf("open handles = %d, want 0", n)
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/data", "data")
+
+	a, err := fs.Open("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := fs.Open("//data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fs.IsOpen("/data") {
+		t.Error("IsOpen = false while the file is open")
+	}
+	a.Close()
+	if !fs.IsOpen("/data") {
+		t.Error("IsOpen = false while a second handle is open")
+	}
+	b.Close()
+	if fs.IsOpen("/data") {
+		t.Error("IsOpen = true after every handle was closed")
+	}
+}
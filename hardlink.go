@@ -0,0 +1,123 @@
+package ptfs
+
+import (
+	"os"
+	"reflect"
+)
+
+// An Inoder is a os.FileInfo, or the value returned by its Sys method, that
+// reports the inode number of the file it describes, for backends whose
+// FileInfo.Sys does not return a *syscall.Stat_t.
+type Inoder interface {
+	Ino() uint64
+}
+
+// HardLinkGroups walks the tree rooted at root and groups the paths of its
+// regular files by inode number, returning the groups of two or more paths,
+// which are hard links to the same file, sorted in the order Walk visits
+// them. The inode number is taken from an Inoder, or from the Ino field of
+// FileInfo.Sys(), such as that of a *syscall.Stat_t, as reported by the
+// directory listing or else by Lstat. If the inode number of a file is not
+// available, HardLinkGroups returns a *os.PathError wrapping
+// ErrNotSupported. Inode numbers are assumed to be unique within the tree,
+// which does not hold for a tree spanning several devices.
+func (f *FileSystem) HardLinkGroups(root string) (map[uint64][]string, error) {
+	paths := make(map[uint64][]string)
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		ino, ok := inode(info)
+		if !ok {
+			// Directory listings may describe files with less detail than
+			// Lstat does.
+			if info, err = f.lstat(name); err != nil {
+				return err
+			}
+			ino, ok = inode(info)
+		}
+		if !ok {
+			return &os.PathError{Op: "hardlinks", Path: name, Err: ErrNotSupported}
+		}
+		paths[ino] = append(paths[ino], name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for ino, names := range paths {
+		if len(names) < 2 {
+			delete(paths, ino)
+		}
+	}
+	return paths, nil
+}
+
+// Duplicates walks the tree rooted at root and groups the paths of its
+// regular files by content, returning the groups of two or more paths with
+// identical content, sorted in the order Walk visits them, keyed by the hex
+// encoded SHA-256 hash of that content. Only files whose size is shared by
+// another file are read and hashed, so files of unique sizes cost nothing
+// beyond the walk. Hard links to the same file, as reported by
+// HardLinkGroups, are reported as duplicates too.
+func (f *FileSystem) Duplicates(root string) (map[string][]string, error) {
+	bySize := make(map[int64][]string)
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			bySize[info.Size()] = append(bySize[info.Size()], name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dups := make(map[string][]string)
+	for _, names := range bySize {
+		if len(names) < 2 {
+			continue
+		}
+		for _, name := range names {
+			sum, err := sha256File(f, name)
+			if err != nil {
+				return nil, err
+			}
+			dups[sum] = append(dups[sum], name)
+		}
+	}
+	for sum, names := range dups {
+		if len(names) < 2 {
+			delete(dups, sum)
+		}
+	}
+	return dups, nil
+}
+
+// inode returns the inode number of a file from an Inoder or from the Ino
+// field of info.Sys(), if present.
+func inode(info os.FileInfo) (uint64, bool) {
+	if i, ok := info.(Inoder); ok {
+		return i.Ino(), true
+	}
+	if i, ok := info.Sys().(Inoder); ok {
+		return i.Ino(), true
+	}
+	v := reflect.ValueOf(info.Sys())
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	ino := v.FieldByName("Ino")
+	if !ino.IsValid() || !ino.CanUint() {
+		return 0, false
+	}
+	return ino.Uint(), true
+}
@@ -0,0 +1,105 @@
+package ptfs_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// inodeFS reports inode numbers for the files listed in inodes.
+type inodeFS struct {
+	absfs.FileSystem
+	inodes map[string]uint64
+}
+
+type inodeInfo struct {
+	os.FileInfo
+	ino uint64
+}
+
+func (i inodeInfo) Ino() uint64 { return i.ino }
+
+func (fs inodeFS) Stat(name string) (os.FileInfo, error) {
+	info, err := fs.FileSystem.Stat(name)
+	if ino, ok := fs.inodes[name]; ok && err == nil {
+		info = inodeInfo{info, ino}
+	}
+	return info, err
+}
+
+func TestHardLinkGroups(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfs.MkdirAll("/root/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"/root/a", "/root/b", "/root/sub/c", "/root/d"} {
+		writeFile(t, mfs, name, "data")
+	}
+	fs, err := ptfs.NewFS(inodeFS{mfs, map[string]uint64{
+		"/root/a":     10,
+		"/root/b":     11,
+		"/root/sub/c": 10,
+		"/root/d":     12,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := fs.HardLinkGroups("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[uint64][]string{10: {"/root/a", "/root/sub/c"}}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("HardLinkGroups = %v, want %v", groups, want)
+	}
+
+	plain, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.HardLinkGroups("/root"); !errors.Is(err, ptfs.ErrNotSupported) {
+		t.Errorf("HardLinkGroups without inodes = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &openLogFS{FileSystem: mfs, opens: make(map[string]int)}
+	fs, err := ptfs.NewFS(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/tree/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/tree/a", "same content")
+	writeFile(t, fs, "/tree/sub/b", "same content")
+	writeFile(t, fs, "/tree/c", "diff content")
+	writeFile(t, fs, "/tree/unique", "unique")
+
+	dups, err := fs.Duplicates("/tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("same content"))
+	want := map[string][]string{hex.EncodeToString(sum[:]): {"/tree/a", "/tree/sub/b"}}
+	if !reflect.DeepEqual(dups, want) {
+		t.Errorf("Duplicates = %v, want %v", dups, want)
+	}
+	if n := backend.count("/tree/unique"); n != 0 {
+		t.Errorf("file of unique size read %d times", n)
+	}
+}
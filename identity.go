@@ -0,0 +1,157 @@
+package ptfs
+
+import (
+	"context"
+	"os"
+	"path"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// WithIdentityACL restricts the context aware methods of the FileSystem,
+// such as OpenFileContext and RemoveContext, to the operations permitted for
+// the identity attached to their context by WithIdentity. Each identity has
+// an Allowlist, whose operations are named after the method without its
+// Context suffix. An operation that is not permitted, or whose context has
+// no identity in acl, fails with a *os.PathError wrapping os.ErrPermission.
+//
+// Only the context aware methods are checked. The methods without a context
+// are not restricted by identity, so code serving several callers must use
+// the context aware methods throughout.
+func WithIdentityACL(acl map[string]Allowlist) Option {
+	return func(f *FileSystem) error {
+		f.identityACL = acl
+		return nil
+	}
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying identity, whose permissions are
+// checked by the context aware methods of the FileSystem.
+func (f *FileSystem) WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// checkIdentity checks that the identity of ctx is permitted op on name. It
+// does nothing unless WithIdentityACL was given.
+func (f *FileSystem) checkIdentity(ctx context.Context, op, name string) error {
+	if f.identityACL == nil {
+		return nil
+	}
+	name = path.Clean(name)
+	identity, ok := ctx.Value(identityKey{}).(string)
+	if list, known := f.identityACL[identity]; ok && known && list.permits(op, name) {
+		return nil
+	}
+	return &os.PathError{Op: op, Path: name, Err: os.ErrPermission}
+}
+
+// OpenContext is Open, checked against the identity of ctx.
+func (f *FileSystem) OpenContext(ctx context.Context, name string) (absfs.File, error) {
+	if err := f.checkIdentity(ctx, "Open", name); err != nil {
+		return nil, err
+	}
+	return f.Open(name)
+}
+
+// OpenFileContext is OpenFile, checked against the identity of ctx.
+func (f *FileSystem) OpenFileContext(ctx context.Context, name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if err := f.checkIdentity(ctx, "OpenFile", name); err != nil {
+		return nil, err
+	}
+	return f.openFileContext(ctx, name, flag, perm)
+}
+
+// CreateContext is Create, checked against the identity of ctx.
+func (f *FileSystem) CreateContext(ctx context.Context, name string) (absfs.File, error) {
+	if err := f.checkIdentity(ctx, "Create", name); err != nil {
+		return nil, err
+	}
+	return f.createContext(ctx, name)
+}
+
+// StatContext is Stat, checked against the identity of ctx.
+func (f *FileSystem) StatContext(ctx context.Context, name string) (os.FileInfo, error) {
+	if err := f.checkIdentity(ctx, "Stat", name); err != nil {
+		return nil, err
+	}
+	return f.Stat(name)
+}
+
+// MkdirContext is Mkdir, checked against the identity of ctx.
+func (f *FileSystem) MkdirContext(ctx context.Context, name string, perm os.FileMode) error {
+	if err := f.checkIdentity(ctx, "Mkdir", name); err != nil {
+		return err
+	}
+	return f.mkdirContext(ctx, name, perm)
+}
+
+// MkdirAllContext is MkdirAll, checked against the identity of ctx.
+func (f *FileSystem) MkdirAllContext(ctx context.Context, name string, perm os.FileMode) error {
+	if err := f.checkIdentity(ctx, "MkdirAll", name); err != nil {
+		return err
+	}
+	return f.mkdirAllContext(ctx, name, perm)
+}
+
+// RemoveContext is Remove, checked against the identity of ctx.
+func (f *FileSystem) RemoveContext(ctx context.Context, name string) error {
+	if err := f.checkIdentity(ctx, "Remove", name); err != nil {
+		return err
+	}
+	return f.removeContext(ctx, name)
+}
+
+// RemoveAllContext is RemoveAll, checked against the identity of ctx.
+func (f *FileSystem) RemoveAllContext(ctx context.Context, name string) error {
+	if err := f.checkIdentity(ctx, "RemoveAll", name); err != nil {
+		return err
+	}
+	return f.removeAllContext(ctx, name)
+}
+
+// RenameContext is Rename, checked against the identity of ctx for both
+// oldname and newname.
+func (f *FileSystem) RenameContext(ctx context.Context, oldname, newname string) error {
+	if err := f.checkIdentity(ctx, "Rename", oldname); err != nil {
+		return err
+	}
+	if err := f.checkIdentity(ctx, "Rename", newname); err != nil {
+		return err
+	}
+	return f.renameContext(ctx, oldname, newname)
+}
+
+// ChmodContext is Chmod, checked against the identity of ctx.
+func (f *FileSystem) ChmodContext(ctx context.Context, name string, mode os.FileMode) error {
+	if err := f.checkIdentity(ctx, "Chmod", name); err != nil {
+		return err
+	}
+	return f.chmodContext(ctx, name, mode)
+}
+
+// ChtimesContext is Chtimes, checked against the identity of ctx.
+func (f *FileSystem) ChtimesContext(ctx context.Context, name string, atime, mtime time.Time) error {
+	if err := f.checkIdentity(ctx, "Chtimes", name); err != nil {
+		return err
+	}
+	return f.chtimesContext(ctx, name, atime, mtime)
+}
+
+// ChownContext is Chown, checked against the identity of ctx.
+func (f *FileSystem) ChownContext(ctx context.Context, name string, uid, gid int) error {
+	if err := f.checkIdentity(ctx, "Chown", name); err != nil {
+		return err
+	}
+	return f.chownContext(ctx, name, uid, gid)
+}
+
+// TruncateContext is Truncate, checked against the identity of ctx.
+func (f *FileSystem) TruncateContext(ctx context.Context, name string, size int64) error {
+	if err := f.checkIdentity(ctx, "Truncate", name); err != nil {
+		return err
+	}
+	return f.truncateContext(ctx, name, size)
+}
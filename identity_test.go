@@ -0,0 +1,65 @@
+package ptfs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWithIdentityACL(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithIdentityACL(map[string]ptfs.Allowlist{
+		"writer": {"*": {"/shared"}},
+		"reader": {"Open": {"/shared"}, "Stat": {"/shared"}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/shared", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writer := fs.WithIdentity(context.Background(), "writer")
+	reader := fs.WithIdentity(context.Background(), "reader")
+
+	f, err := fs.CreateContext(writer, "/shared/file")
+	if err != nil {
+		t.Fatalf("writer Create = %v", err)
+	}
+	f.WriteString("data")
+	f.Close()
+
+	if _, err := fs.CreateContext(reader, "/shared/file"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("reader Create = %v, want ErrPermission", err)
+	}
+	if _, err := fs.OpenFileContext(reader, "/shared/file", os.O_WRONLY, 0); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("reader OpenFile = %v, want ErrPermission", err)
+	}
+	if err := fs.RemoveContext(reader, "/shared/file"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("reader Remove = %v, want ErrPermission", err)
+	}
+	f, err = fs.OpenContext(reader, "/shared/file")
+	if err != nil {
+		t.Fatalf("reader Open = %v", err)
+	}
+	f.Close()
+
+	// Outside its prefixes, and without an identity, nothing is permitted.
+	if _, err := fs.CreateContext(writer, "/other"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("writer Create outside /shared = %v, want ErrPermission", err)
+	}
+	if _, err := fs.StatContext(context.Background(), "/shared/file"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Stat without identity = %v, want ErrPermission", err)
+	}
+
+	// Methods without a context are not restricted.
+	if err := fs.Remove("/shared/file"); err != nil {
+		t.Errorf("Remove = %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package ptfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewFromIOFS returns a read only FileSystem serving the files of fsys, such
+// as an embed.FS. Paths are rooted at "/", which corresponds to "." in fsys.
+// Operations that would modify the filesystem fail with a *os.PathError
+// wrapping syscall.EROFS.
+func NewFromIOFS(fsys fs.FS, opts ...Option) (*FileSystem, error) {
+	return NewFS(&ioFS{fsys: fsys, cwd: "/"}, opts...)
+}
+
+// ioFS adapts an fs.FS to absfs.FileSystem.
+type ioFS struct {
+	fsys fs.FS
+
+	mu  sync.Mutex
+	cwd string
+}
+
+// name translates an absfs path to an fs.FS path.
+func (f *ioFS) name(op, name string) (string, error) {
+	if !path.IsAbs(name) {
+		f.mu.Lock()
+		name = path.Join(f.cwd, name)
+		f.mu.Unlock()
+	}
+	name = path.Clean(name)[1:]
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", &os.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return name, nil
+}
+
+func erofs(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: syscall.EROFS}
+}
+
+func (f *ioFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, erofs("open", name)
+	}
+	return f.Open(name)
+}
+
+func (f *ioFS) Open(name string) (absfs.File, error) {
+	fname, err := f.name("open", name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := f.fsys.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	return &ioFile{f: file, name: name}, nil
+}
+
+func (f *ioFS) Stat(name string) (os.FileInfo, error) {
+	fname, err := f.name("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(f.fsys, fname)
+}
+
+func (f *ioFS) Chdir(dir string) error {
+	info, err := f.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "chdir", Path: dir, Err: syscall.ENOTDIR}
+	}
+	fname, _ := f.name("chdir", dir)
+	f.mu.Lock()
+	f.cwd = path.Join("/", fname)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *ioFS) Getwd() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cwd, nil
+}
+
+func (f *ioFS) Separator() uint8     { return '/' }
+func (f *ioFS) ListSeparator() uint8 { return ':' }
+func (f *ioFS) TempDir() string      { return "/tmp" }
+
+func (f *ioFS) Create(name string) (absfs.File, error)            { return nil, erofs("open", name) }
+func (f *ioFS) Mkdir(name string, perm os.FileMode) error         { return erofs("mkdir", name) }
+func (f *ioFS) MkdirAll(name string, perm os.FileMode) error      { return erofs("mkdir", name) }
+func (f *ioFS) Remove(name string) error                          { return erofs("remove", name) }
+func (f *ioFS) RemoveAll(name string) error                       { return erofs("remove", name) }
+func (f *ioFS) Truncate(name string, size int64) error            { return erofs("truncate", name) }
+func (f *ioFS) Chmod(name string, mode os.FileMode) error         { return erofs("chmod", name) }
+func (f *ioFS) Chown(name string, uid, gid int) error             { return erofs("chown", name) }
+func (f *ioFS) Chtimes(name string, atime, mtime time.Time) error { return erofs("chtimes", name) }
+
+func (f *ioFS) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EROFS}
+}
+
+// ioFile adapts an fs.File to absfs.File.
+type ioFile struct {
+	f    fs.File
+	name string
+}
+
+func (f *ioFile) Name() string { return f.name }
+
+func (f *ioFile) Read(p []byte) (int, error) { return f.f.Read(p) }
+
+func (f *ioFile) ReadAt(b []byte, off int64) (int, error) {
+	if r, ok := f.f.(io.ReaderAt); ok {
+		return r.ReadAt(b, off)
+	}
+	return 0, &os.PathError{Op: "readat", Path: f.name, Err: ErrNotSupported}
+}
+
+func (f *ioFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.f.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, &os.PathError{Op: "seek", Path: f.name, Err: ErrNotSupported}
+}
+
+func (f *ioFile) Write(p []byte) (int, error)              { return 0, erofs("write", f.name) }
+func (f *ioFile) WriteAt(b []byte, off int64) (int, error) { return 0, erofs("write", f.name) }
+func (f *ioFile) WriteString(s string) (int, error)        { return 0, erofs("write", f.name) }
+func (f *ioFile) Truncate(size int64) error                { return erofs("truncate", f.name) }
+
+func (f *ioFile) Close() error               { return f.f.Close() }
+func (f *ioFile) Stat() (os.FileInfo, error) { return f.f.Stat() }
+func (f *ioFile) Sync() error                { return nil }
+
+func (f *ioFile) Readdir(n int) ([]os.FileInfo, error) {
+	d, ok := f.f.(fs.ReadDirFile)
+	if !ok {
+		return nil, &os.PathError{Op: "readdir", Path: f.name, Err: syscall.ENOTDIR}
+	}
+	entries, err := d.ReadDir(n)
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, ierr := entry.Info()
+		if ierr != nil {
+			return infos, ierr
+		}
+		infos = append(infos, info)
+	}
+	return infos, err
+}
+
+func (f *ioFile) Readdirnames(n int) ([]string, error) {
+	infos, err := f.Readdir(n)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, err
+}
@@ -0,0 +1,51 @@
+package ptfs_test
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/absfs/ptfs"
+)
+
+func TestNewFromIOFS(t *testing.T) {
+	fs, err := ptfs.NewFromIOFS(fstest.MapFS{
+		"static/app.js": {Data: []byte("console.log(1)")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fs.Stat("/static/app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 14 {
+		t.Errorf("size = %d, want 14", info.Size())
+	}
+	if got := readAll(t, fs, "/static/app.js"); got != "console.log(1)" {
+		t.Errorf("content = %q", got)
+	}
+
+	if err := fs.Chdir("/static"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "app.js"); got != "console.log(1)" {
+		t.Errorf("content relative to the working directory = %q", got)
+	}
+	names, err := readDirNames(fs, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "app.js" {
+		t.Errorf("directory entries = %q, want [app.js]", names)
+	}
+
+	if _, err := fs.Create("/new"); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("Create error = %v, want EROFS", err)
+	}
+	if err := fs.Remove("/static/app.js"); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("Remove error = %v, want EROFS", err)
+	}
+}
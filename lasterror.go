@@ -0,0 +1,87 @@
+package ptfs
+
+import (
+	"sync"
+	"time"
+)
+
+// WithLastErrors records the most recent error returned by each operation,
+// to be reported by LastError and LastErrorTime, for example by a health
+// check. If clearOnSuccess is true, a successful operation clears the error
+// recorded for that operation.
+func WithLastErrors(clearOnSuccess bool) Option {
+	return func(f *FileSystem) error {
+		f.errs = &errorLog{clear: clearOnSuccess}
+		return nil
+	}
+}
+
+// LastError returns the most recent error returned by the named operation,
+// such as "Open" or "Remove", or nil if none has been recorded. Errors are
+// only recorded when the FileSystem was created with WithLastErrors.
+func (f *FileSystem) LastError(op string) error {
+	if f.errs == nil {
+		return nil
+	}
+	return f.errs.get(op).err
+}
+
+// LastErrorTime returns the time the error reported by LastError was
+// returned, or the zero time if none has been recorded.
+func (f *FileSystem) LastErrorTime(op string) time.Time {
+	if f.errs == nil {
+		return time.Time{}
+	}
+	return f.errs.get(op).time
+}
+
+// done is deferred by every operation, started at start, to apply any
+// latency, categorize and record the error it returns, and record metrics.
+func (f *FileSystem) done(op string, start time.Time, err *error) {
+	if f.categorizeErrors {
+		*err = categorize(*err)
+	}
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+	if f.metrics != nil {
+		f.metrics.record(op, time.Since(start), *err)
+	}
+	if f.errs != nil {
+		f.errs.record(op, *err)
+	}
+}
+
+type errorLog struct {
+	clear bool
+
+	mu   sync.Mutex
+	last map[string]errorEntry
+}
+
+type errorEntry struct {
+	err  error
+	time time.Time
+}
+
+func (l *errorLog) record(op string, err error) {
+	if err == nil && !l.clear {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil {
+		delete(l.last, op)
+		return
+	}
+	if l.last == nil {
+		l.last = make(map[string]errorEntry)
+	}
+	l.last[op] = errorEntry{err, time.Now()}
+}
+
+func (l *errorLog) get(op string) errorEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last[op]
+}
@@ -0,0 +1,46 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestLastError(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithLastErrors(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.LastError("Remove"); err != nil {
+		t.Fatalf("LastError before any failure = %v", err)
+	}
+	removeErr := fs.Remove("/missing")
+	if removeErr == nil {
+		t.Fatal("removing a missing file succeeded")
+	}
+	if err := fs.LastError("Remove"); err != removeErr || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LastError = %v, want %v", err, removeErr)
+	}
+	if fs.LastErrorTime("Remove").IsZero() {
+		t.Error("LastErrorTime is zero after a failure")
+	}
+	if err := fs.LastError("Open"); err != nil {
+		t.Errorf("LastError of another operation = %v, want nil", err)
+	}
+
+	writeFile(t, fs, "/present", "")
+	if err := fs.Remove("/present"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.LastError("Remove"); err != nil {
+		t.Errorf("LastError after a success = %v, want nil", err)
+	}
+}
@@ -0,0 +1,56 @@
+package ptfs
+
+import (
+	"bufio"
+	"sync"
+)
+
+// maxLineSize is the longest line Lines will return. Longer lines end the
+// scan with bufio.ErrTooLong.
+const maxLineSize = 16 << 20
+
+// Lines opens the named file and returns a channel yielding its lines without
+// line endings, and a function that stops reading and returns the error that
+// ended the scan, if any. The channel is closed and the file is closed when
+// the end of the file is reached, when an error occurs, or when the stop
+// function is called. The stop function must always be called, and waits for
+// the file to be closed. Lines longer than 16MiB end the scan with
+// bufio.ErrTooLong.
+func (f *FileSystem) Lines(name string) (<-chan string, func() error, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := make(chan string)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	var scanErr error
+	go func() {
+		defer close(done)
+		defer close(lines)
+
+		s := bufio.NewScanner(file)
+		s.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	scan:
+		for s.Scan() {
+			select {
+			case lines <- s.Text():
+			case <-stop:
+				break scan
+			}
+		}
+		scanErr = s.Err()
+		if err := file.Close(); scanErr == nil {
+			scanErr = err
+		}
+	}()
+
+	var once sync.Once
+	wait := func() error {
+		once.Do(func() { close(stop) })
+		<-done
+		return scanErr
+	}
+	return lines, wait, nil
+}
@@ -0,0 +1,50 @@
+package ptfs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestLines(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/log", "one\ntwo\r\n\nfour")
+
+	lines, wait, err := fs.Lines("/log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"one", "two", "", "four"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+
+	lines, wait, err = fs.Lines("/log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := <-lines; line != "one" {
+		t.Errorf("first line = %q, want %q", line, "one")
+	}
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fs.OpenHandles()); n != 0 {
+		t.Errorf("open handles after stop = %d, want 0", n)
+	}
+}
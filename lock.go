@@ -0,0 +1,99 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"sync"
+
+	"github.com/absfs/absfs"
+)
+
+// OpenShared opens the named file for reading while holding a shared lock on
+// its path. Any number of shared locks may be held at once, but a shared lock
+// is not granted while an exclusive lock from OpenExclusive is held, and
+// OpenShared blocks until it is released. The lock is released by calling the
+// returned function or by closing the file, whichever happens first.
+//
+// Locks are advisory and held in memory by the FileSystem, they only
+// coordinate callers using OpenShared and OpenExclusive on the same
+// FileSystem value.
+func (f *FileSystem) OpenShared(name string) (absfs.File, func(), error) {
+	return f.openLocked(name, os.O_RDONLY, 0, false)
+}
+
+// OpenExclusive opens the named file as OpenFile does while holding an
+// exclusive lock on its path. The lock is not granted while any other shared
+// or exclusive lock is held on the path, and OpenExclusive blocks until they
+// are released. The lock is released by calling the returned function or by
+// closing the file, whichever happens first.
+//
+// Locks are advisory, see OpenShared.
+func (f *FileSystem) OpenExclusive(name string, flag int, perm os.FileMode) (absfs.File, func(), error) {
+	return f.openLocked(name, flag, perm, true)
+}
+
+func (f *FileSystem) openLocked(name string, flag int, perm os.FileMode, exclusive bool) (absfs.File, func(), error) {
+	unlock := f.locks.lock(name, exclusive)
+	file, err := f.OpenFile(name, flag, perm)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	pf := file.(*File)
+	pf.onClose = append(pf.onClose, func() error {
+		unlock()
+		return nil
+	})
+	return file, unlock, nil
+}
+
+// pathLocks is a set of read write locks keyed by cleaned path. Locks are
+// created on demand and discarded when no longer held.
+type pathLocks struct {
+	mu    sync.Mutex
+	locks map[string]*pathLock
+}
+
+type pathLock struct {
+	sync.RWMutex
+	refs int
+}
+
+// lock acquires the lock for name and returns a function releasing it. The
+// returned function may be called more than once.
+func (l *pathLocks) lock(name string, exclusive bool) func() {
+	name = path.Clean(name)
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*pathLock)
+	}
+	pl := l.locks[name]
+	if pl == nil {
+		pl = new(pathLock)
+		l.locks[name] = pl
+	}
+	pl.refs++
+	l.mu.Unlock()
+
+	if exclusive {
+		pl.Lock()
+	} else {
+		pl.RLock()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if exclusive {
+				pl.Unlock()
+			} else {
+				pl.RUnlock()
+			}
+			l.mu.Lock()
+			if pl.refs--; pl.refs == 0 {
+				delete(l.locks, name)
+			}
+			l.mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package ptfs_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestOpenShared(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/data", "data")
+
+	a, unlockA, err := fs.OpenShared("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := fs.OpenShared("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened := make(chan struct{})
+	go func() {
+		f, unlock, err := fs.OpenExclusive("/data", os.O_RDWR, 0)
+		if err != nil {
+			t.Error(err)
+		} else {
+			unlock()
+			f.Close()
+		}
+		close(opened)
+	}()
+
+	select {
+	case <-opened:
+		t.Fatal("exclusive open did not wait for shared locks")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlockA()
+	a.Close()
+	select {
+	case <-opened:
+		t.Fatal("exclusive open did not wait for all shared locks")
+	case <-time.After(20 * time.Millisecond):
+	}
+	b.Close()
+	select {
+	case <-opened:
+	case <-time.After(time.Second):
+		t.Fatal("exclusive open was not granted after shared locks were released")
+	}
+}
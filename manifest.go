@@ -0,0 +1,125 @@
+package ptfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// A ManifestEntry describes the expected content of a file checked by
+// VerifyManifest.
+type ManifestEntry struct {
+	Size   int64
+	SHA256 string // hex encoded; if empty only the size is checked
+}
+
+// A ManifestErrorKind classifies a mismatch found by VerifyManifest.
+type ManifestErrorKind int
+
+const (
+	// ManifestMissing is a file that does not exist, or is not a regular
+	// file.
+	ManifestMissing ManifestErrorKind = iota + 1
+
+	// ManifestWrongSize is a file whose size differs from the manifest.
+	ManifestWrongSize
+
+	// ManifestWrongHash is a file of the expected size whose content hash
+	// differs from the manifest.
+	ManifestWrongHash
+)
+
+func (k ManifestErrorKind) String() string {
+	switch k {
+	case ManifestMissing:
+		return "missing"
+	case ManifestWrongSize:
+		return "wrong size"
+	case ManifestWrongHash:
+		return "wrong hash"
+	}
+	return "unknown"
+}
+
+// A ManifestError describes a file that does not match its manifest entry.
+type ManifestError struct {
+	Path      string
+	Kind      ManifestErrorKind
+	Want, Got string // the expected and actual size or hash, if any
+}
+
+func (e ManifestError) Error() string {
+	if e.Want == "" {
+		return e.Path + ": " + e.Kind.String()
+	}
+	return e.Path + ": " + e.Kind.String() + ": want " + e.Want + ", got " + e.Got
+}
+
+// VerifyManifest checks that every file in manifest exists as a regular file
+// with the size and SHA-256 hash recorded for it, and returns the mismatches
+// sorted by path. Hashes are compared case insensitively, and only for files
+// of the expected size. Files are hashed as they are read, so they are never
+// held in memory. An error reading a file other than it not existing stops
+// the check and is returned.
+func (f *FileSystem) VerifyManifest(manifest map[string]ManifestEntry) ([]ManifestError, error) {
+	names := make([]string, 0, len(manifest))
+	for name := range manifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mismatches []ManifestError
+	for _, name := range names {
+		want := manifest[name]
+		info, err := f.Stat(name)
+		if errors.Is(err, os.ErrNotExist) {
+			mismatches = append(mismatches, ManifestError{Path: name, Kind: ManifestMissing})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !info.Mode().IsRegular():
+			mismatches = append(mismatches, ManifestError{Path: name, Kind: ManifestMissing})
+		case info.Size() != want.Size:
+			mismatches = append(mismatches, ManifestError{
+				Path: name, Kind: ManifestWrongSize,
+				Want: strconv.FormatInt(want.Size, 10), Got: strconv.FormatInt(info.Size(), 10),
+			})
+		case want.SHA256 != "":
+			sum, err := sha256File(f, name)
+			if err != nil {
+				return nil, err
+			}
+			if !strings.EqualFold(sum, want.SHA256) {
+				mismatches = append(mismatches, ManifestError{
+					Path: name, Kind: ManifestWrongHash, Want: want.SHA256, Got: sum,
+				})
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// sha256File returns the hex encoded SHA-256 hash of the content of the named
+// file in fs.
+func sha256File(fs absfs.FileSystem, name string) (string, error) {
+	file, err := fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,73 @@
+package ptfs_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestVerifyManifest(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/app", 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"/app/bin":    "binary",
+		"/app/config": "key=value",
+		"/app/readme": "hello",
+	}
+	manifest := make(map[string]ptfs.ManifestEntry)
+	for name, content := range files {
+		writeFile(t, fs, name, content)
+		sum := sha256.Sum256([]byte(content))
+		manifest[name] = ptfs.ManifestEntry{Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+	}
+
+	mismatches, err := fs.VerifyManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("matching tree: %v", mismatches)
+	}
+
+	writeFile(t, fs, "/app/config", "key=VALUE")
+	mismatches, err = fs.VerifyManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || mismatches[0].Path != "/app/config" || mismatches[0].Kind != ptfs.ManifestWrongHash {
+		t.Fatalf("tampered file: %v", mismatches)
+	}
+
+	writeFile(t, fs, "/app/readme", "hello!")
+	if err := fs.Remove("/app/bin"); err != nil {
+		t.Fatal(err)
+	}
+	mismatches, err = fs.VerifyManifest(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ptfs.ManifestErrorKind{ptfs.ManifestMissing, ptfs.ManifestWrongHash, ptfs.ManifestWrongSize}
+	if len(mismatches) != len(want) {
+		t.Fatalf("got %v, want kinds %v", mismatches, want)
+	}
+	for i, m := range mismatches {
+		if m.Kind != want[i] {
+			t.Errorf("mismatch %d = %v, want kind %v", i, m, want[i])
+		}
+	}
+	if got := mismatches[2].Error(); got != "/app/readme: wrong size: want 5, got 6" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,141 @@
+package ptfs
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics collects the number of operations performed by a FileSystem, the
+// number that failed, and optionally a histogram of their durations, per
+// operation. A Metrics may be shared by several FileSystems.
+type Metrics struct {
+	buckets []time.Duration
+
+	mu  sync.Mutex
+	ops map[string]*opMetrics
+}
+
+type opMetrics struct {
+	count, errors uint64
+	counts        []uint64 // per bucket, not cumulative
+	sum           time.Duration
+}
+
+// NewMetrics returns an empty Metrics. If latencyBuckets are given, the
+// durations of operations are also collected in a histogram with those
+// upper bounds.
+func NewMetrics(latencyBuckets ...time.Duration) *Metrics {
+	buckets := append([]time.Duration(nil), latencyBuckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	return &Metrics{buckets: buckets, ops: make(map[string]*opMetrics)}
+}
+
+// WithMetrics collects metrics for the operations of the FileSystem in m.
+func WithMetrics(m *Metrics) Option {
+	return func(f *FileSystem) error {
+		f.metrics = m
+		return nil
+	}
+}
+
+func (m *Metrics) record(op string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o := m.ops[op]
+	if o == nil {
+		o = &opMetrics{counts: make([]uint64, len(m.buckets))}
+		m.ops[op] = o
+	}
+	o.count++
+	if err != nil {
+		o.errors++
+	}
+	if len(m.buckets) > 0 {
+		o.sum += d
+		if i := sort.Search(len(m.buckets), func(i int) bool { return d <= m.buckets[i] }); i < len(m.buckets) {
+			o.counts[i]++
+		}
+	}
+}
+
+// Count returns the number of times op was performed.
+func (m *Metrics) Count(op string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o := m.ops[op]; o != nil {
+		return o.count
+	}
+	return 0
+}
+
+// Errors returns the number of times op failed.
+func (m *Metrics) Errors(op string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if o := m.ops[op]; o != nil {
+		return o.errors
+	}
+	return 0
+}
+
+// WritePrometheus writes the metrics to w in the Prometheus text exposition
+// format, as the counters ptfs_operations_total and
+// ptfs_operation_errors_total and, if latency buckets were given to
+// NewMetrics, the histogram ptfs_operation_duration_seconds, each labeled
+// with the operation name as op.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	ops := make([]string, 0, len(m.ops))
+	snap := make(map[string]opMetrics, len(m.ops))
+	for op, o := range m.ops {
+		ops = append(ops, op)
+		snap[op] = opMetrics{o.count, o.errors, append([]uint64(nil), o.counts...), o.sum}
+	}
+	m.mu.Unlock()
+	sort.Strings(ops)
+
+	bw := bufio.NewWriter(w)
+	counter := func(name, help string, value func(opMetrics) uint64) {
+		bw.WriteString("# HELP " + name + " " + help + "\n")
+		bw.WriteString("# TYPE " + name + " counter\n")
+		for _, op := range ops {
+			bw.WriteString(name + "{op=" + labelValue(op) + "} " + strconv.FormatUint(value(snap[op]), 10) + "\n")
+		}
+	}
+	counter("ptfs_operations_total", "Number of filesystem operations performed.",
+		func(o opMetrics) uint64 { return o.count })
+	counter("ptfs_operation_errors_total", "Number of filesystem operations that failed.",
+		func(o opMetrics) uint64 { return o.errors })
+
+	if len(m.buckets) > 0 {
+		const name = "ptfs_operation_duration_seconds"
+		bw.WriteString("# HELP " + name + " Duration of filesystem operations.\n")
+		bw.WriteString("# TYPE " + name + " histogram\n")
+		for _, op := range ops {
+			o, label := snap[op], labelValue(op)
+			var cumulative uint64
+			for i, b := range m.buckets {
+				cumulative += o.counts[i]
+				bw.WriteString(name + "_bucket{op=" + label + ",le=\"" + seconds(b) + "\"} " + strconv.FormatUint(cumulative, 10) + "\n")
+			}
+			bw.WriteString(name + "_bucket{op=" + label + ",le=\"+Inf\"} " + strconv.FormatUint(o.count, 10) + "\n")
+			bw.WriteString(name + "_sum{op=" + label + "} " + seconds(o.sum) + "\n")
+			bw.WriteString(name + "_count{op=" + label + "} " + strconv.FormatUint(o.count, 10) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// labelValue returns s quoted as a Prometheus label value.
+func labelValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
@@ -0,0 +1,57 @@
+package ptfs_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+var (
+	promComment = regexp.MustCompile(`^# (HELP|TYPE) [a-zA-Z_:][a-zA-Z0-9_:]* .+$`)
+	promSample  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*(\{[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\.)*"(,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\.)*")*\})? [-+]?([0-9.eE+-]+|Inf)$`)
+)
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := ptfs.NewMetrics(time.Millisecond, time.Second)
+	fs, err := ptfs.NewFS(mfs, ptfs.WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/a", "a")
+	fs.Stat("/a")
+	fs.Stat("/missing")
+	if m.Count("Stat") != 2 || m.Errors("Stat") != 1 {
+		t.Fatalf("Stat count %d, errors %d, want 2, 1", m.Count("Stat"), m.Errors("Stat"))
+	}
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if !promComment.MatchString(line) && !promSample.MatchString(line) {
+			t.Errorf("invalid line %q", line)
+		}
+	}
+	for _, want := range []string{
+		`ptfs_operations_total{op="Stat"} 2`,
+		`ptfs_operation_errors_total{op="Stat"} 1`,
+		`ptfs_operations_total{op="Create"} 1`,
+		`ptfs_operation_duration_seconds_bucket{op="Stat",le="+Inf"} 2`,
+		`ptfs_operation_duration_seconds_count{op="Stat"} 2`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package ptfs
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/absfs/absfs"
+)
+
+// WithStrictMmap makes Mmap return ErrNotSupported instead of reading the
+// file into memory when the file cannot be memory mapped.
+func WithStrictMmap() Option {
+	return func(f *FileSystem) error {
+		f.strictMmap = true
+		return nil
+	}
+}
+
+// Mmap returns the content of the named file, and a function releasing it
+// that must be called once the content is no longer used. The file is
+// followed through the layers of this and any nested FileSystem, such as
+// WithBandwidth or WithPageCache, that pass its content through unchanged.
+// If that reaches an *os.File, on platforms supporting mmap, the file is
+// memory mapped read only, and if it reaches a file set with Override, its
+// content is returned as is; either must not be modified or used after it
+// is released. Otherwise, as when a layer changing content such as WithEOL
+// applies to the file, the file is read into memory and releasing it does
+// nothing, or, with WithStrictMmap, Mmap fails with an error wrapping
+// ErrNotSupported.
+func (f *FileSystem) Mmap(name string) ([]byte, func() error, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	src, unchanged := mmapSource(file)
+	if cf, ok := src.(*cachedFile); ok && unchanged {
+		return cf.data, func() error { return nil }, nil
+	}
+	if osf, ok := src.(*os.File); ok && unchanged {
+		info, err := osf.Stat()
+		if err != nil {
+			return nil, nil, err
+		}
+		data, unmap, err := mmapFile(osf, info.Size())
+		if err == nil {
+			var once sync.Once
+			return data, func() (err error) {
+				once.Do(func() { err = unmap() })
+				return err
+			}, nil
+		}
+		if !isNotSupported(err) {
+			return nil, nil, &os.PathError{Op: "mmap", Path: name, Err: err}
+		}
+	}
+	if f.strictMmap {
+		return nil, nil, &os.PathError{Op: "mmap", Path: name, Err: ErrNotSupported}
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
+
+// mmapSource returns the file that file reads from, through the layers of
+// this and nested FileSystems, and whether none of those layers changes its
+// content.
+func mmapSource(file absfs.File) (absfs.File, bool) {
+	for {
+		switch l := file.(type) {
+		case *eolFile:
+			return file, false
+		case *File:
+			file = l.f
+			continue
+		}
+		inner, ok := unwrapLayer(file)
+		if !ok {
+			return file, true
+		}
+		file = inner
+	}
+}
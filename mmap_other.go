@@ -0,0 +1,10 @@
+//go:build !unix
+
+package ptfs
+
+import "os"
+
+// mmapFile is not supported on this platform.
+func mmapFile(file *os.File, size int64) ([]byte, func() error, error) {
+	return nil, nil, ErrNotSupported
+}
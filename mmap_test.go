@@ -0,0 +1,135 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// osOpenFS opens files from dir of the host filesystem.
+type osOpenFS struct {
+	absfs.FileSystem
+	dir string
+}
+
+func (fs osOpenFS) Open(name string) (absfs.File, error) {
+	return os.Open(filepath.Join(fs.dir, filepath.FromSlash(name)))
+}
+
+func TestMmap(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/data", "in memory")
+
+	data, unmap, err := fs.Mmap("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "in memory" {
+		t.Fatalf("Mmap = %q, want %q", data, "in memory")
+	}
+	if err := unmap(); err != nil {
+		t.Fatal(err)
+	}
+
+	strict, err := ptfs.NewFS(mfs, ptfs.WithStrictMmap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := strict.Mmap("/data"); !errors.Is(err, ptfs.ErrNotSupported) {
+		t.Fatalf("strict Mmap of a memfs file = %v, want %v", err, ptfs.ErrNotSupported)
+	}
+}
+
+func TestMmapOSFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data"), []byte("on disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(osOpenFS{mfs, dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, unmap, err := fs.Mmap("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "on disk" {
+		t.Fatalf("Mmap = %q, want %q", data, "on disk")
+	}
+	if err := unmap(); err != nil {
+		t.Fatal(err)
+	}
+	if err := unmap(); err != nil {
+		t.Fatalf("second unmap = %v", err)
+	}
+}
+
+func TestMmapThroughLayers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data"), []byte("one\r\ntwo\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := ptfs.NewFS(osOpenFS{mfs, dir}, ptfs.WithStrictMmap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := plain.Mmap("/data"); errors.Is(err, ptfs.ErrNotSupported) {
+		t.Skip("mmap is not supported on this platform")
+	}
+
+	layered, err := ptfs.NewFS(osOpenFS{mfs, dir}, ptfs.WithBandwidth(1<<30), ptfs.WithPageCache(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := ptfs.NewFS(layered, ptfs.WithStrictMmap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, unmap, err := nested.Mmap("/data")
+	if err != nil {
+		t.Fatalf("strict Mmap through layers = %v", err)
+	}
+	if string(data) != "one\r\ntwo\r\n" {
+		t.Errorf("Mmap = %q", data)
+	}
+	unmap()
+
+	// An override is served from memory as is.
+	nested.Override("/data", []byte("override"))
+	data, unmap, err = nested.Mmap("/data")
+	if err != nil || string(data) != "override" {
+		t.Errorf("strict Mmap of an override = %q, %v", data, err)
+	} else {
+		unmap()
+	}
+
+	// Translating line endings changes the content, which is not mapped.
+	eol, err := ptfs.NewEOLFS(osOpenFS{mfs, dir}, ptfs.EOLReadLF, ptfs.WithStrictMmap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := eol.Mmap("/data"); !errors.Is(err, ptfs.ErrNotSupported) {
+		t.Errorf("strict Mmap with EOL translation = %v, want %v", err, ptfs.ErrNotSupported)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package ptfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps size bytes of file into memory read only.
+func mmapFile(file *os.File, size int64) ([]byte, func() error, error) {
+	if size == 0 {
+		return []byte{}, func() error { return nil }, nil
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
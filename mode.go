@@ -0,0 +1,54 @@
+package ptfs
+
+import "os"
+
+// WithCanonicalMode normalizes the modes reported by Stat and Lstat, which
+// filesystems report inconsistently. The mode of a directory always has
+// os.ModeDir set, even if the underlying filesystem only reports it through
+// IsDir, and every mode has at most one type bit, the permission bits and
+// the setuid, setgid and sticky bits, and no other bits such as
+// os.ModeAppend or os.ModeTemporary. The other FileInfo methods are
+// unchanged.
+func WithCanonicalMode() Option {
+	return func(f *FileSystem) error {
+		f.canonicalMode = true
+		return nil
+	}
+}
+
+// canonical returns info with a canonical mode if WithCanonicalMode was
+// given.
+func (f *FileSystem) canonical(info os.FileInfo) os.FileInfo {
+	if !f.canonicalMode || info == nil {
+		return info
+	}
+	return canonicalInfo{info, canonicalMode(info)}
+}
+
+func canonicalMode(info os.FileInfo) os.FileMode {
+	mode := info.Mode()
+	canon := mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	switch {
+	case info.IsDir():
+		canon |= os.ModeDir
+	case mode&os.ModeSymlink != 0:
+		canon |= os.ModeSymlink
+	case mode&os.ModeNamedPipe != 0:
+		canon |= os.ModeNamedPipe
+	case mode&os.ModeSocket != 0:
+		canon |= os.ModeSocket
+	case mode&os.ModeCharDevice != 0:
+		canon |= os.ModeDevice | os.ModeCharDevice
+	case mode&os.ModeDevice != 0:
+		canon |= os.ModeDevice
+	}
+	return canon
+}
+
+type canonicalInfo struct {
+	os.FileInfo
+	mode os.FileMode
+}
+
+func (i canonicalInfo) Mode() os.FileMode { return i.mode }
+func (i canonicalInfo) IsDir() bool       { return i.mode.IsDir() }
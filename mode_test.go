@@ -0,0 +1,67 @@
+package ptfs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// quirkyFS reports directories without os.ModeDir and files with extra
+// mode bits.
+type quirkyFS struct {
+	absfs.FileSystem
+}
+
+func (fs quirkyFS) Stat(name string) (os.FileInfo, error) {
+	info, err := fs.FileSystem.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return quirkyInfo{info, info.Mode() &^ os.ModeDir}, nil
+	}
+	return quirkyInfo{info, info.Mode() | os.ModeTemporary | os.ModeAppend}, nil
+}
+
+type quirkyInfo struct {
+	os.FileInfo
+	mode os.FileMode
+}
+
+func (i quirkyInfo) Mode() os.FileMode { return i.mode }
+
+func TestWithCanonicalMode(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(quirkyFS{mfs}, ptfs.WithCanonicalMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/dir", 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/file", "data")
+	if err := fs.Chmod("/file", 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fs.Stat("/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode() != os.ModeDir|0750 || !info.IsDir() || info.Name() != "dir" {
+		t.Errorf("directory mode = %v, IsDir %v, want %v", info.Mode(), info.IsDir(), os.ModeDir|0750)
+	}
+	info, err = fs.Stat("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode() != 0640 || info.Size() != 4 {
+		t.Errorf("file mode = %v, size %d, want %v, 4", info.Mode(), info.Size(), os.FileMode(0640))
+	}
+}
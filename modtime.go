@@ -0,0 +1,87 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"time"
+)
+
+// WithFixedModTime sets the access and modification times of every file
+// written through the FileSystem to t when it is closed, and replaces the
+// times passed to Chtimes with t. This makes archives built from the files
+// reproducible regardless of when they were written, but means the
+// filesystem no longer records real timestamps for those files.
+func WithFixedModTime(t time.Time) Option {
+	return func(f *FileSystem) error {
+		f.fixedTime = &t
+		return nil
+	}
+}
+
+// WithModTimeLocation makes the FileInfo returned by Stat and Lstat report
+// ModTime in loc, as time.Time.In does. Only the presentation changes: the
+// instant is the same, and the times stored by the underlying filesystem are
+// not modified.
+func WithModTimeLocation(loc *time.Location) Option {
+	return func(f *FileSystem) error {
+		f.modTimeLoc = loc
+		return nil
+	}
+}
+
+// inLocation returns info with its modification time in the location set by
+// WithModTimeLocation, if any.
+func (f *FileSystem) inLocation(info os.FileInfo) os.FileInfo {
+	if f.modTimeLoc == nil || info == nil {
+		return info
+	}
+	return locatedInfo{info, f.modTimeLoc}
+}
+
+type locatedInfo struct {
+	os.FileInfo
+	loc *time.Location
+}
+
+func (i locatedInfo) ModTime() time.Time { return i.FileInfo.ModTime().In(i.loc) }
+
+// TimePair holds the access and modification times of a file, as passed to
+// Chtimes.
+type TimePair struct {
+	Atime time.Time
+	Mtime time.Time
+}
+
+// WithChtimesSkipMissing makes ChtimesMany skip paths that do not exist,
+// rather than report them as errors.
+func WithChtimesSkipMissing() Option {
+	return func(f *FileSystem) error {
+		f.chtimesSkipMissing = true
+		return nil
+	}
+}
+
+// ChtimesMany sets the access and modification times of every path in
+// times, in sorted order, as archive extraction does once all files are
+// written. It carries on past failures, and returns the errors of all the
+// paths that failed joined with errors.Join, or nil if none did. With
+// WithChtimesSkipMissing, paths that do not exist are skipped.
+func (f *FileSystem) ChtimesMany(times map[string]TimePair) error {
+	names := make([]string, 0, len(times))
+	for name := range times {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		t := times[name]
+		err := f.Chtimes(name, t.Atime, t.Mtime)
+		if err == nil || f.chtimesSkipMissing && errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestFixedModTime(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	fs, err := ptfs.NewFS(mfs, ptfs.WithFixedModTime(fixed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, fs, "/artifact", "content")
+	assertModTime(t, fs, "/artifact", fixed)
+
+	if err := fs.Chtimes("/artifact", time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	assertModTime(t, fs, "/artifact", fixed)
+}
+
+func assertModTime(t *testing.T, fs *ptfs.FileSystem, name string, want time.Time) {
+	t.Helper()
+	info, err := fs.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(want) {
+		t.Errorf("%s: modtime = %v, want %v", name, info.ModTime(), want)
+	}
+}
+
+func TestWithModTimeLocation(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/file", "data")
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := mfs.Chtimes("/file", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	loc := time.FixedZone("UTC+9", 9*60*60)
+	fs, err := ptfs.NewFS(mfs, ptfs.WithModTimeLocation(loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fs.Stat("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := info.ModTime()
+	if got.Location() != loc {
+		t.Errorf("location = %v, want %v", got.Location(), loc)
+	}
+	if !got.Equal(mtime) {
+		t.Errorf("ModTime = %v, want the instant %v", got, mtime)
+	}
+	if got.Hour() != 21 {
+		t.Errorf("hour = %d, want 21", got.Hour())
+	}
+	if backend, _ := mfs.Stat("/file"); !backend.ModTime().Equal(mtime) {
+		t.Errorf("backend ModTime = %v, want %v", backend.ModTime(), mtime)
+	}
+}
+
+func TestChtimesMany(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/a", "a")
+	writeFile(t, fs, "/b", "b")
+	day := func(d int) time.Time { return time.Date(2000, 1, d, 0, 0, 0, 0, time.UTC) }
+	times := map[string]ptfs.TimePair{
+		"/a":       {Atime: day(1), Mtime: day(2)},
+		"/b":       {Atime: day(3), Mtime: day(4)},
+		"/missing": {Atime: day(5), Mtime: day(6)},
+	}
+
+	err = fs.ChtimesMany(times)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ChtimesMany error = %v, want ErrNotExist", err)
+	}
+	assertModTime(t, fs, "/a", day(2))
+	assertModTime(t, fs, "/b", day(4))
+
+	skip, err := ptfs.NewFS(mfs, ptfs.WithChtimesSkipMissing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	times["/a"] = ptfs.TimePair{Atime: day(7), Mtime: day(8)}
+	if err := skip.ChtimesMany(times); err != nil {
+		t.Errorf("ChtimesMany with WithChtimesSkipMissing: %v", err)
+	}
+	assertModTime(t, skip, "/a", day(8))
+}
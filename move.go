@@ -0,0 +1,84 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// MoveInto moves src into the directory destDir, keeping its base name, like
+// "mv src destDir/". If destDir already contains a file with that name,
+// MoveInto returns a *os.LinkError wrapping os.ErrExist.
+func (f *FileSystem) MoveInto(src, destDir string) error {
+	return f.moveInto(src, destDir, false)
+}
+
+// MoveIntoOverwrite moves src into the directory destDir as MoveInto does,
+// replacing any file of the same name in destDir.
+func (f *FileSystem) MoveIntoOverwrite(src, destDir string) error {
+	return f.moveInto(src, destDir, true)
+}
+
+func (f *FileSystem) moveInto(src, destDir string, overwrite bool) error {
+	sep := string(f.Separator())
+	base := strings.TrimRight(src, sep)
+	if i := strings.LastIndex(base, sep); i >= 0 {
+		base = base[i+1:]
+	}
+	dst := strings.TrimRight(destDir, sep) + sep + base
+
+	info, err := f.Stat(destDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.LinkError{Op: "move", Old: src, New: dst, Err: syscall.ENOTDIR}
+	}
+	if !overwrite {
+		if _, err := f.Stat(dst); err == nil {
+			return &os.LinkError{Op: "move", Old: src, New: dst, Err: os.ErrExist}
+		}
+	}
+
+	err = f.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	// src and dst are on different devices, so copy and delete instead.
+	if err := f.CopyFile(src, dst); err != nil {
+		return err
+	}
+	return f.Remove(src)
+}
+
+// RenameWithBackup renames oldpath to newpath, first renaming any existing
+// newpath to newpath+backupSuffix, replacing a previous backup, so that the
+// replaced version is kept. If renaming oldpath then fails, the backup is
+// renamed back to newpath and the error is returned, joined with the error
+// of the rollback if that fails too.
+//
+// The replacement takes two renames and is not atomic as a whole: another
+// process may briefly find newpath missing, and if the program stops between
+// the renames, or the rollback itself fails, newpath is left missing with its
+// previous version at the backup path.
+func (f *FileSystem) RenameWithBackup(oldpath, newpath, backupSuffix string) error {
+	_, err := f.lstat(newpath)
+	if errors.Is(err, os.ErrNotExist) {
+		return f.Rename(oldpath, newpath)
+	}
+	if err != nil {
+		return err
+	}
+	backup := newpath + backupSuffix
+	if err := f.Rename(newpath, backup); err != nil {
+		return err
+	}
+	if err := f.Rename(oldpath, newpath); err != nil {
+		if rerr := f.Rename(backup, newpath); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+		return err
+	}
+	return nil
+}
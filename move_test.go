@@ -0,0 +1,127 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestMoveInto(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/a", "/b"} {
+		if err := fs.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, fs, "/a/x.txt", "x")
+
+	if err := fs.MoveInto("/a/x.txt", "/b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/b/x.txt"); got != "x" {
+		t.Errorf("moved content = %q, want %q", got, "x")
+	}
+	if _, err := fs.Stat("/a/x.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("source still exists: %v", err)
+	}
+
+	writeFile(t, fs, "/a/x.txt", "y")
+	if err := fs.MoveInto("/a/x.txt", "/b"); !errors.Is(err, os.ErrExist) {
+		t.Errorf("MoveInto onto an existing file: error = %v, want ErrExist", err)
+	}
+	if err := fs.MoveIntoOverwrite("/a/x.txt", "/b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/b/x.txt"); got != "y" {
+		t.Errorf("moved content = %q, want %q", got, "y")
+	}
+}
+
+func TestRenameWithBackup(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/config", "v1")
+	writeFile(t, fs, "/config.new", "v2")
+
+	if err := fs.RenameWithBackup("/config.new", "/config", ".bak"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/config"); got != "v2" {
+		t.Errorf("/config = %q, want %q", got, "v2")
+	}
+	if got := readAll(t, fs, "/config.bak"); got != "v1" {
+		t.Errorf("/config.bak = %q, want %q", got, "v1")
+	}
+
+	// Without an existing file there is nothing to back up.
+	writeFile(t, fs, "/other.new", "other")
+	if err := fs.RenameWithBackup("/other.new", "/other", ".bak"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("/other.bak"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat(/other.bak) = %v, want ErrNotExist", err)
+	}
+
+	// A failed rename restores the backup.
+	if err := fs.RenameWithBackup("/missing", "/config", ".old"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("RenameWithBackup from a missing file = %v, want ErrNotExist", err)
+	}
+	if got := readAll(t, fs, "/config"); got != "v2" {
+		t.Errorf("/config after rollback = %q, want %q", got, "v2")
+	}
+	if _, err := fs.Stat("/config.old"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat(/config.old) = %v, want ErrNotExist", err)
+	}
+}
+
+// renameFailFS fails renames from the path from.
+type renameFailFS struct {
+	absfs.FileSystem
+	from string
+}
+
+var errRenameFail = errors.New("rename failed")
+
+func (fs renameFailFS) Rename(oldname, newname string) error {
+	if oldname == fs.from {
+		return errRenameFail
+	}
+	return fs.FileSystem.Rename(oldname, newname)
+}
+
+func TestRenameWithBackupRollbackFails(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(renameFailFS{mfs, "/config.bak"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/config", "v1")
+
+	err = fs.RenameWithBackup("/missing", "/config", ".bak")
+	if !errors.Is(err, os.ErrNotExist) || !errors.Is(err, errRenameFail) {
+		t.Fatalf("RenameWithBackup = %v, want both the rename and rollback errors", err)
+	}
+	if got := readAll(t, fs, "/config.bak"); got != "v1" {
+		t.Errorf("/config.bak = %q, want %q", got, "v1")
+	}
+}
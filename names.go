@@ -0,0 +1,63 @@
+package ptfs
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/absfs/absfs"
+)
+
+// WithUTF8Names rejects the creation of files whose names are not valid
+// UTF-8 with a *os.PathError wrapping syscall.EINVAL. Names are checked when
+// they are created by Create, OpenFile with O_CREATE, Mkdir, MkdirAll, the
+// new name of Rename and the new name of Symlink. Other operations are not
+// checked, so existing files with invalid names can still be read and
+// removed.
+func WithUTF8Names() Option {
+	return func(f *FileSystem) error {
+		sep := f.fs.Separator()
+		f.validNames = append(f.validNames, func(name string) bool {
+			return validUTF8Path(name, sep)
+		})
+		return nil
+	}
+}
+
+// NewNamingFS returns a FileSystem enforcing a naming convention, such as
+// lowercase names, as set by WithNamingConvention.
+func NewNamingFS(fs absfs.FileSystem, valid func(path string) bool, opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithNamingConvention(valid)}, opts...)...)
+}
+
+// WithNamingConvention rejects the creation of files for which valid returns
+// false with a *os.PathError wrapping syscall.EINVAL. valid is called with
+// the path given by the caller, and is checked by the same operations as
+// WithUTF8Names, so existing files are unaffected.
+func WithNamingConvention(valid func(path string) bool) Option {
+	return func(f *FileSystem) error {
+		f.validNames = append(f.validNames, valid)
+		return nil
+	}
+}
+
+// checkCreate returns an error if op may not create a file named name.
+func (f *FileSystem) checkCreate(op, name string) error {
+	for _, valid := range f.validNames {
+		if !valid(name) {
+			return &os.PathError{Op: op, Path: name, Err: syscall.EINVAL}
+		}
+	}
+	return nil
+}
+
+// validUTF8Path reports whether every component of name is valid UTF-8.
+func validUTF8Path(name string, sep uint8) bool {
+	for _, elem := range strings.Split(name, string(sep)) {
+		if !utf8.ValidString(elem) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package ptfs_test
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestUTF8Names(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithUTF8Names())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := "/bad\xff\xfename"
+	if _, err := fs.Create(invalid); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("Create error = %v, want EINVAL", err)
+	}
+	if err := fs.Mkdir(invalid, 0755); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("Mkdir error = %v, want EINVAL", err)
+	}
+	writeFile(t, fs, "/ok", "ok")
+	if err := fs.Rename("/ok", invalid); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("Rename error = %v, want EINVAL", err)
+	}
+
+	if err := fs.Mkdir("/日本語", 0755); err != nil {
+		t.Errorf("Mkdir with a valid unicode name: %v", err)
+	}
+	writeFile(t, fs, "/日本語/файл.txt", "ok")
+
+	// Existing files with invalid names can still be read.
+	writeFile(t, mfs, invalid, "legacy")
+	if got := readAll(t, fs, invalid); got != "legacy" {
+		t.Errorf("content = %q, want %q", got, "legacy")
+	}
+}
+
+func TestNewNamingFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/Legacy", "legacy")
+	lowercase := func(name string) bool { return strings.ToLower(name) == name }
+	fs, err := ptfs.NewNamingFS(mfs, lowercase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fs.Create("/Upper"); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("Create(/Upper) = %v, want EINVAL", err)
+	}
+	if err := fs.MkdirAll("/dir/Sub", 0755); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("MkdirAll(/dir/Sub) = %v, want EINVAL", err)
+	}
+	writeFile(t, fs, "/lower", "lower")
+	if err := fs.Rename("/lower", "/Renamed"); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("Rename to /Renamed = %v, want EINVAL", err)
+	}
+
+	// Existing files with nonconforming names can still be used.
+	if got := readAll(t, fs, "/Legacy"); got != "legacy" {
+		t.Errorf("read /Legacy = %q, want %q", got, "legacy")
+	}
+	if err := fs.Rename("/Legacy", "/legacy"); err != nil {
+		t.Errorf("Rename from /Legacy = %v", err)
+	}
+}
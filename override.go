@@ -0,0 +1,75 @@
+package ptfs
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// Override makes the named file read as content, without changing the
+// underlying filesystem, until ClearOverride is called. Open, and OpenFile
+// without flags that write or create, return a read only file holding
+// content, and Stat describes it as a regular file of len(content) bytes
+// with permissions 0444, whether or not the file exists. Other operations,
+// including writes to the file, are passed through. Paths are matched as
+// given, after cleaning, so an override of an absolute path does not apply
+// to a relative path naming the same file. content must not be modified
+// after calling Override. Overrides are meant for injecting file content
+// in tests.
+func (f *FileSystem) Override(name string, content []byte) {
+	f.overrides.mu.Lock()
+	defer f.overrides.mu.Unlock()
+	if f.overrides.files == nil {
+		f.overrides.files = make(map[string]*overrideInfo)
+	}
+	name = path.Clean(name)
+	f.overrides.files[name] = &overrideInfo{path.Base(name), content, time.Now()}
+}
+
+// ClearOverride removes the override of the named file set by Override,
+// restoring access to the underlying file.
+func (f *FileSystem) ClearOverride(name string) {
+	f.overrides.mu.Lock()
+	defer f.overrides.mu.Unlock()
+	delete(f.overrides.files, path.Clean(name))
+}
+
+type overrides struct {
+	mu    sync.RWMutex
+	files map[string]*overrideInfo
+}
+
+func (o *overrides) stat(name string) (*overrideInfo, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	info, ok := o.files[path.Clean(name)]
+	return info, ok
+}
+
+// openOverride returns a file reading the override of name, if there is one.
+func (f *FileSystem) openOverride(name string) (absfs.File, bool) {
+	info, ok := f.overrides.stat(name)
+	if !ok {
+		return nil, false
+	}
+	file := &cachedFile{Reader: bytes.NewReader(info.content), data: info.content, name: name, info: info}
+	return f.file(name, os.O_RDONLY, file), true
+}
+
+// overrideInfo describes a file set with Override.
+type overrideInfo struct {
+	name    string
+	content []byte
+	modTime time.Time
+}
+
+func (i *overrideInfo) Name() string       { return i.name }
+func (i *overrideInfo) Size() int64        { return int64(len(i.content)) }
+func (i *overrideInfo) Mode() os.FileMode  { return 0444 }
+func (i *overrideInfo) ModTime() time.Time { return i.modTime }
+func (i *overrideInfo) IsDir() bool        { return false }
+func (i *overrideInfo) Sys() any           { return nil }
@@ -0,0 +1,58 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestOverride(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/config", "real")
+
+	fs.Override("/config", []byte("injected"))
+	fs.Override("/missing", []byte("new"))
+	if got := readAll(t, fs, "/config"); got != "injected" {
+		t.Errorf("/config = %q, want %q", got, "injected")
+	}
+	if got := readAll(t, fs, "/missing"); got != "new" {
+		t.Errorf("/missing = %q, want %q", got, "new")
+	}
+	info, err := fs.Stat("/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len("injected")) || !info.Mode().IsRegular() {
+		t.Errorf("Stat = size %d, mode %v", info.Size(), info.Mode())
+	}
+	f, err := fs.Open("/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("x")); err == nil {
+		t.Error("Write to an overridden file succeeded")
+	}
+	f.Close()
+	if got := readAll(t, mfs, "/config"); got != "real" {
+		t.Errorf("backend /config = %q, want %q", got, "real")
+	}
+
+	fs.ClearOverride("/config")
+	fs.ClearOverride("/missing")
+	if got := readAll(t, fs, "/config"); got != "real" {
+		t.Errorf("/config after ClearOverride = %q, want %q", got, "real")
+	}
+	if _, err := fs.Stat("/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat(/missing) after ClearOverride = %v, want ErrNotExist", err)
+	}
+}
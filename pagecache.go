@@ -0,0 +1,213 @@
+package ptfs
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// cachePageSize is the size of the pages kept by WithPageCache.
+const cachePageSize = 64 << 10
+
+// WithPageCache makes files opened for reading only through the FileSystem
+// share an in-memory cache of their content, in pages of 64KiB, so that
+// many handles reading the same hot file read each page from the underlying
+// filesystem once. Pages are cached by path, modification time and size as
+// reported by Stat when the file is opened, so a handle opened after the
+// file is modified reads the new content, while handles opened before keep
+// reading the version they opened. At most maxBytes of content is cached,
+// and the least recently used pages are evicted first, including the pages
+// of replaced versions. Files whose underlying handles do not support
+// ReadAt are not cached.
+func WithPageCache(maxBytes int64) Option {
+	return func(f *FileSystem) error {
+		if maxBytes <= 0 {
+			return errors.New("ptfs: non-positive page cache size")
+		}
+		f.pageCache = &pageCache{
+			max:   maxBytes,
+			pages: make(map[pageKey]*list.Element),
+			lru:   list.New(),
+		}
+		return nil
+	}
+}
+
+// cacheFile wraps file, opened for reading as name, to read through the
+// page cache, if the file can be cached.
+func (c *pageCache) cacheFile(name string, file absfs.File) absfs.File {
+	if _, ok := file.(*cachedFile); ok {
+		return file
+	}
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return file
+	}
+	if _, err := file.ReadAt(nil, 0); isNotSupported(err) {
+		return file
+	}
+	return &pageCacheFile{
+		File:  file,
+		cache: c,
+		key:   pageKey{name: name, modTime: info.ModTime(), size: info.Size()},
+	}
+}
+
+// pageKey identifies a page of a version of a file. Page is the index of
+// the page in the file.
+type pageKey struct {
+	name    string
+	modTime time.Time
+	size    int64
+	page    int64
+}
+
+// pageCache is a least recently used cache of file pages.
+type pageCache struct {
+	max int64
+
+	mu    sync.Mutex
+	size  int64 // bytes held by loaded pages
+	pages map[pageKey]*list.Element
+	lru   *list.List // of *cachePage, most recently used first
+}
+
+type cachePage struct {
+	key    pageKey
+	ready  chan struct{} // closed once data and err are set
+	data   []byte
+	err    error
+	loaded bool // counted in the cache size
+}
+
+// page returns the data of the page identified by key, calling load to read
+// it if it is not cached. Concurrent calls for a page that is being loaded
+// wait for the load to finish rather than load it again. Failed loads are
+// not cached.
+func (c *pageCache) page(key pageKey, load func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if e, ok := c.pages[key]; ok {
+		c.lru.MoveToFront(e)
+		p := e.Value.(*cachePage)
+		c.mu.Unlock()
+		<-p.ready
+		return p.data, p.err
+	}
+	p := &cachePage{key: key, ready: make(chan struct{})}
+	e := c.lru.PushFront(p)
+	c.pages[key] = e
+	c.mu.Unlock()
+
+	data, err := load()
+
+	c.mu.Lock()
+	p.data, p.err = data, err
+	if c.pages[key] == e {
+		if err != nil {
+			c.remove(e)
+		} else {
+			p.loaded = true
+			c.size += int64(len(data))
+			for c.size > c.max {
+				c.remove(c.lru.Back())
+			}
+		}
+	}
+	c.mu.Unlock()
+	close(p.ready)
+	return data, err
+}
+
+// remove removes the page e from the cache. The caller holds c.mu.
+func (c *pageCache) remove(e *list.Element) {
+	p := c.lru.Remove(e).(*cachePage)
+	delete(c.pages, p.key)
+	if p.loaded {
+		c.size -= int64(len(p.data))
+	}
+}
+
+// pageCacheFile reads a file opened for reading through a pageCache. It
+// keeps its own offset, so the offset of the underlying file is unused.
+type pageCacheFile struct {
+	absfs.File
+	cache *pageCache
+	key   pageKey
+
+	mu  sync.Mutex
+	off int64
+}
+
+func (f *pageCacheFile) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n, err := f.ReadAt(p, f.off)
+	f.off += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *pageCacheFile) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &os.PathError{Op: "readat", Path: f.Name(), Err: syscall.EINVAL}
+	}
+	n := 0
+	for n < len(b) {
+		if off >= f.key.size {
+			return n, io.EOF
+		}
+		key := f.key
+		key.page = off / cachePageSize
+		data, err := f.cache.page(key, func() ([]byte, error) { return f.load(key.page) })
+		if err != nil {
+			return n, err
+		}
+		start := off - key.page*cachePageSize
+		if start >= int64(len(data)) {
+			// The file shrank after it was opened.
+			return n, io.EOF
+		}
+		m := copy(b[n:], data[start:])
+		n += m
+		off += int64(m)
+	}
+	return n, nil
+}
+
+// load reads the page at index page from the underlying file.
+func (f *pageCacheFile) load(page int64) ([]byte, error) {
+	off := page * cachePageSize
+	buf := make([]byte, min(cachePageSize, f.key.size-off))
+	n, err := f.File.ReadAt(buf, off)
+	if err == io.EOF {
+		err = nil
+	}
+	return buf[:n], err
+}
+
+func (f *pageCacheFile) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.key.size
+	default:
+		return 0, &os.PathError{Op: "seek", Path: f.Name(), Err: syscall.EINVAL}
+	}
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.Name(), Err: syscall.EINVAL}
+	}
+	f.off = offset
+	return offset, nil
+}
@@ -0,0 +1,190 @@
+package ptfs_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// readCountFS counts the reads of its files that transfer data.
+type readCountFS struct {
+	absfs.FileSystem
+	reads atomic.Int32
+}
+
+func (fs *readCountFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	f, err := fs.FileSystem.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &readCountFile{f, fs}, nil
+}
+
+func (fs *readCountFS) Open(name string) (absfs.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+type readCountFile struct {
+	absfs.File
+	fs *readCountFS
+}
+
+func (f *readCountFile) Read(p []byte) (int, error) {
+	if len(p) > 0 {
+		f.fs.reads.Add(1)
+	}
+	return f.File.Read(p)
+}
+
+func (f *readCountFile) ReadAt(b []byte, off int64) (int, error) {
+	if len(b) > 0 {
+		f.fs.reads.Add(1)
+	}
+	return f.File.ReadAt(b, off)
+}
+
+func TestPageCache(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &readCountFS{FileSystem: mfs}
+	fs, err := ptfs.NewFS(backend, ptfs.WithPageCache(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/hot", "hot content")
+
+	h1, err := fs.Open("/hot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h1.Close()
+	h2, err := fs.Open("/hot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h2.Close()
+	for _, h := range []absfs.File{h1, h2} {
+		data, err := io.ReadAll(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "hot content" {
+			t.Errorf("read %q, want %q", data, "hot content")
+		}
+	}
+	if n := backend.reads.Load(); n != 1 {
+		t.Errorf("underlying reads = %d, want 1", n)
+	}
+
+	// A handle opened after the file is modified reads the new version.
+	writeFile(t, mfs, "/hot", "new content!")
+	later := time.Now().Add(time.Hour)
+	if err := mfs.Chtimes("/hot", later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/hot"); got != "new content!" {
+		t.Errorf("read after modification = %q, want %q", got, "new content!")
+	}
+	if n := backend.reads.Load(); n != 2 {
+		t.Errorf("underlying reads after modification = %d, want 2", n)
+	}
+}
+
+func TestPageCachePages(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &readCountFS{FileSystem: mfs}
+	fs, err := ptfs.NewFS(backend, ptfs.WithPageCache(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := bytes.Repeat([]byte("0123456789abcdef"), 10000)
+	writeFile(t, mfs, "/big", string(content))
+
+	f, err := fs.Open("/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// Read across the boundary of the first two pages.
+	buf := make([]byte, 1000)
+	if _, err := f.ReadAt(buf, 65000); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, content[65000:66000]) {
+		t.Error("ReadAt across pages returned wrong content")
+	}
+	if _, err := f.Seek(-100, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tail, content[len(content)-100:]) {
+		t.Errorf("read after Seek = %q", tail)
+	}
+
+	// Concurrent readers share the pages, each read once.
+	writeFile(t, mfs, "/shared", string(content))
+	backend.reads.Store(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := fs.Open("/shared")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer f.Close()
+			data, err := io.ReadAll(f)
+			if err != nil || !bytes.Equal(data, content) {
+				t.Errorf("concurrent read: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := backend.reads.Load(); n != 3 {
+		t.Errorf("underlying reads by concurrent readers = %d, want 3", n)
+	}
+}
+
+func TestPageCacheEviction(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &readCountFS{FileSystem: mfs}
+	fs, err := ptfs.NewFS(backend, ptfs.WithPageCache(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/a", "0123456789")
+	writeFile(t, mfs, "/b", "abcdefghij")
+
+	for _, name := range []string{"/a", "/a", "/b", "/a"} {
+		readAll(t, fs, name)
+	}
+	// /a is read again once /b has evicted it.
+	if n := backend.reads.Load(); n != 3 {
+		t.Errorf("underlying reads = %d, want 3", n)
+	}
+
+	if _, err := ptfs.NewFS(mfs, ptfs.WithPageCache(0)); err == nil {
+		t.Error("NewFS with a zero page cache size succeeded")
+	}
+}
@@ -0,0 +1,147 @@
+package ptfs
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// Join joins any number of path elements into a single path, separated by
+// the separator of the underlying filesystem, and cleans the result as
+// path.Join does. Empty elements are ignored, and if every element is empty
+// Join returns "".
+func (f *FileSystem) Join(elem ...string) string {
+	slashed := make([]string, len(elem))
+	for i, e := range elem {
+		slashed[i] = f.swapSlash(e)
+	}
+	return f.swapSlash(path.Join(slashed...))
+}
+
+// Split splits p immediately following the final separator of the
+// underlying filesystem into a directory and file name, as path.Split does.
+func (f *FileSystem) Split(p string) (dir, file string) {
+	dir, file = path.Split(f.swapSlash(p))
+	return f.swapSlash(dir), f.swapSlash(file)
+}
+
+// Base returns the last element of p, using the separator of the underlying
+// filesystem, as path.Base does.
+func (f *FileSystem) Base(p string) string {
+	return f.swapSlash(path.Base(f.swapSlash(p)))
+}
+
+// Dir returns all but the last element of p, using the separator of the
+// underlying filesystem, as path.Dir does.
+func (f *FileSystem) Dir(p string) string {
+	return f.swapSlash(path.Dir(f.swapSlash(p)))
+}
+
+// Rel returns a relative path that is lexically equivalent to target when
+// joined to base, using the separator of the underlying filesystem, as
+// filepath.Rel does. Both paths are cleaned first. An error is returned if
+// target cannot be made relative to base, because only one of them is
+// absolute, because they are on different volumes such as `C:` and `D:`, or
+// because base contains ".." elements that target does not.
+func (f *FileSystem) Rel(base, target string) (string, error) {
+	b, t := path.Clean(f.swapSlash(base)), path.Clean(f.swapSlash(target))
+	broot, b := splitRoot(b)
+	troot, t := splitRoot(t)
+	if broot != troot {
+		return "", errors.New("Rel: can't make " + target + " relative to " + base)
+	}
+	if b == t {
+		return ".", nil
+	}
+	var belem, telem []string
+	if b != "" && b != "." {
+		belem = strings.Split(b, "/")
+	}
+	if t != "" && t != "." {
+		telem = strings.Split(t, "/")
+	}
+	i := 0
+	for i < len(belem) && i < len(telem) && belem[i] == telem[i] {
+		i++
+	}
+	rel := make([]string, 0, len(belem)-i+len(telem)-i)
+	for _, e := range belem[i:] {
+		if e == ".." {
+			return "", errors.New("Rel: can't make " + target + " relative to " + base)
+		}
+		rel = append(rel, "..")
+	}
+	rel = append(rel, telem[i:]...)
+	return f.swapSlash(strings.Join(rel, "/")), nil
+}
+
+// CommonPrefix returns the longest path that is an ancestor of, or equal
+// to, every one of paths, comparing whole elements separated by the
+// separator of the underlying filesystem, so "/ab" and "/abc" share "/" and
+// not "/ab". The paths are cleaned first, and compared lexically, without
+// consulting the filesystem. If the paths have no deeper common ancestor,
+// CommonPrefix returns their shared root, such as "/" or `C:\`, "/" if
+// their roots differ, and "." if they are relative paths with no common
+// element. With no paths, it returns "/".
+func (f *FileSystem) CommonPrefix(paths ...string) string {
+	if len(paths) == 0 {
+		return f.swapSlash("/")
+	}
+	var root string
+	var common []string
+	for i, p := range paths {
+		r, rest := splitRoot(path.Clean(f.swapSlash(p)))
+		var elem []string
+		if rest != "" && rest != "." {
+			elem = strings.Split(rest, "/")
+		}
+		if i == 0 {
+			root, common = r, elem
+			continue
+		}
+		if r != root {
+			return f.swapSlash("/")
+		}
+		n := 0
+		for n < len(common) && n < len(elem) && common[n] == elem[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if root == "" && len(common) == 0 {
+		return "."
+	}
+	return f.swapSlash(root + strings.Join(common, "/"))
+}
+
+// splitRoot splits a cleaned, slash separated path into its root, which is a
+// leading volume name such as "C:", a leading '/', or both, and the rest.
+func splitRoot(p string) (root, rest string) {
+	if i := strings.IndexByte(p, '/'); i > 0 && p[i-1] == ':' {
+		root, p = p[:i], p[i:]
+	} else if strings.HasSuffix(p, ":") && !strings.Contains(p, "/") {
+		return p, ""
+	}
+	if strings.HasPrefix(p, "/") {
+		return root + "/", strings.TrimPrefix(p, "/")
+	}
+	return root, p
+}
+
+// swapSlash swaps the separator of the underlying filesystem with '/', so
+// that p can be handled by package path and the result swapped back.
+func (f *FileSystem) swapSlash(p string) string {
+	sep := f.Separator()
+	if sep == '/' {
+		return p
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case rune(sep):
+			return '/'
+		case '/':
+			return rune(sep)
+		}
+		return r
+	}, p)
+}
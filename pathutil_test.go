@@ -0,0 +1,145 @@
+package ptfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// backslashFS reports '\\' as its separator.
+type backslashFS struct {
+	absfs.FileSystem
+}
+
+func (backslashFS) Separator() uint8 { return '\\' }
+
+func TestPathHelpers(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slash, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backslash, err := ptfs.NewFS(backslashFS{mfs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fs                        *ptfs.FileSystem
+		elem                      []string
+		join, dir, file, base, up string
+	}{
+		{slash, []string{"/a", "b/../c", "d.txt"}, "/a/c/d.txt", "/a/c/", "d.txt", "d.txt", "/a/c"},
+		{backslash, []string{`\a`, `b\..\c`, "d.txt"}, `\a\c\d.txt`, `\a\c\`, "d.txt", "d.txt", `\a\c`},
+		{backslash, []string{`\a`, "b/c"}, `\a\b/c`, `\a\`, "b/c", "b/c", `\a`},
+	}
+	for _, tt := range tests {
+		join := tt.fs.Join(tt.elem...)
+		if join != tt.join {
+			t.Errorf("Join(%q) = %q, want %q", tt.elem, join, tt.join)
+		}
+		if dir, file := tt.fs.Split(join); dir != tt.dir || file != tt.file {
+			t.Errorf("Split(%q) = %q, %q, want %q, %q", join, dir, file, tt.dir, tt.file)
+		}
+		if base := tt.fs.Base(join); base != tt.base {
+			t.Errorf("Base(%q) = %q, want %q", join, base, tt.base)
+		}
+		if dir := tt.fs.Dir(join); dir != tt.up {
+			t.Errorf("Dir(%q) = %q, want %q", join, dir, tt.up)
+		}
+	}
+}
+
+func TestRel(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slash, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backslash, err := ptfs.NewFS(backslashFS{mfs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fs           *ptfs.FileSystem
+		base, target string
+		want         string
+	}{
+		{slash, "/a/b", "/a/b/c/d", "c/d"},
+		{slash, "/a/b", "/a/b", "."},
+		{slash, "/a/b/c", "/a/x", "../../x"},
+		{slash, "/", "/a", "a"},
+		{slash, "a/b", "a/c", "../c"},
+		{backslash, `\a\b`, `\a\b\c\d`, `c\d`},
+		{backslash, `C:\a\b`, `C:\a\x`, `..\x`},
+	}
+	for _, tt := range tests {
+		got, err := tt.fs.Rel(tt.base, tt.target)
+		if err != nil || got != tt.want {
+			t.Errorf("Rel(%q, %q) = %q, %v, want %q", tt.base, tt.target, got, err, tt.want)
+		}
+	}
+
+	bad := []struct {
+		fs           *ptfs.FileSystem
+		base, target string
+	}{
+		{slash, "/a", "b"},
+		{slash, "a", "/b"},
+		{slash, "../a", "b"},
+		{backslash, `C:\a`, `D:\a`},
+	}
+	for _, tt := range bad {
+		if got, err := tt.fs.Rel(tt.base, tt.target); err == nil {
+			t.Errorf("Rel(%q, %q) = %q, want error", tt.base, tt.target, got)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slash, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backslash, err := ptfs.NewFS(backslashFS{mfs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fs    *ptfs.FileSystem
+		paths []string
+		want  string
+	}{
+		{slash, []string{"/a/b/c", "/a/b/d", "/a/e"}, "/a"},
+		{slash, []string{"/a/b/c", "/a/b/d"}, "/a/b"},
+		{slash, []string{"/ab", "/abc"}, "/"},
+		{slash, []string{"/x/y", "/z"}, "/"},
+		{slash, []string{"/a/b", "/a/b/"}, "/a/b"},
+		{slash, []string{"/a/b"}, "/a/b"},
+		{slash, []string{"a/b", "a/c"}, "a"},
+		{slash, []string{"a", "b"}, "."},
+		{slash, []string{"/a", "a"}, "/"},
+		{slash, nil, "/"},
+		{backslash, []string{`C:\a\b`, `C:\a\c`}, `C:\a`},
+		{backslash, []string{`C:\a`, `D:\a`}, `\`},
+	}
+	for _, tt := range tests {
+		if got := tt.fs.CommonPrefix(tt.paths...); got != tt.want {
+			t.Errorf("CommonPrefix(%q) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ptfs
+
+import (
+	"os"
+
+	"github.com/absfs/absfs"
+)
+
+// NewPeriodicSyncFS returns a FileSystem whose files opened for writing call
+// Sync each time syncEvery bytes have been written since the last sync, as
+// set by WithPeriodicSync.
+func NewPeriodicSyncFS(fs absfs.FileSystem, syncEvery int64, opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithPeriodicSync(syncEvery)}, opts...)...)
+}
+
+// WithPeriodicSync makes files opened for writing call Sync on the
+// underlying file each time syncEvery bytes have been written to them since
+// the last sync. A crash then loses at most about syncEvery bytes of data
+// written to each file, in exchange for a Sync every syncEvery bytes: smaller
+// values lose less data but write more slowly. A syncEvery of 0 or less
+// disables periodic syncing.
+func WithPeriodicSync(syncEvery int64) Option {
+	return func(f *FileSystem) error {
+		f.syncEvery = syncEvery
+		return nil
+	}
+}
+
+type syncFile struct {
+	absfs.File
+	every   int64
+	written int64
+}
+
+// wrote records n bytes written, and syncs the file if enough have been
+// written since the last sync.
+func (f *syncFile) wrote(n int, err error) (int, error) {
+	f.written += int64(n)
+	if err == nil && f.written >= f.every {
+		f.written = 0
+		if err = f.File.Sync(); err != nil {
+			err = &os.PathError{Op: "sync", Path: f.Name(), Err: err}
+		}
+	}
+	return n, err
+}
+
+func (f *syncFile) Write(p []byte) (int, error) {
+	return f.wrote(f.File.Write(p))
+}
+
+func (f *syncFile) WriteAt(b []byte, off int64) (int, error) {
+	return f.wrote(f.File.WriteAt(b, off))
+}
+
+func (f *syncFile) WriteString(s string) (int, error) {
+	return f.wrote(f.File.WriteString(s))
+}
+
+func (f *syncFile) Sync() error {
+	f.written = 0
+	return f.File.Sync()
+}
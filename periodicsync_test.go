@@ -0,0 +1,64 @@
+package ptfs_test
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// syncCountingFS counts the calls to Sync of files it creates.
+type syncCountingFS struct {
+	absfs.FileSystem
+	syncs *atomic.Int32
+}
+
+func (fs syncCountingFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return syncCountingFile{f, fs.syncs}, nil
+}
+
+type syncCountingFile struct {
+	absfs.File
+	syncs *atomic.Int32
+}
+
+func (f syncCountingFile) Sync() error {
+	f.syncs.Add(1)
+	return f.File.Sync()
+}
+
+func TestNewPeriodicSyncFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var syncs atomic.Int32
+	fs, err := ptfs.NewPeriodicSyncFS(syncCountingFS{mfs, &syncs}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fs.Create("/log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 30; i++ {
+		if _, err := f.WriteString(strings.Repeat("x", 10)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+	if n := syncs.Load(); n < 3 {
+		t.Fatalf("writing 300 bytes synced %d times, want at least 3", n)
+	}
+	if got := readAll(t, fs, "/log"); len(got) != 300 {
+		t.Fatalf("file holds %d bytes, want 300", len(got))
+	}
+}
@@ -0,0 +1,52 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"sort"
+)
+
+// A PermSnapshot maps paths to the permission bits, including the setuid,
+// setgid and sticky bits, recorded for them by SavePerms.
+type PermSnapshot map[string]os.FileMode
+
+// SavePerms records the permission bits of every file and directory in the
+// tree rooted at root, including root, keyed by path as visited by Walk.
+// Symbolic links are not recorded, since Chmod would change their targets.
+func (f *FileSystem) SavePerms(root string) (PermSnapshot, error) {
+	snap := make(PermSnapshot)
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			snap[name] = info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// RestorePerms applies Chmod to every path in snap with its recorded
+// permission bits. Paths that no longer exist are skipped. Entries are
+// restored before the directories containing them, so a directory whose
+// saved permissions deny access does not prevent restoring its entries.
+// RestorePerms tries every path and returns the first error encountered.
+func (f *FileSystem) RestorePerms(snap PermSnapshot) error {
+	names := make([]string, 0, len(snap))
+	for name := range snap {
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	var first error
+	for _, name := range names {
+		err := f.Chmod(name, snap[name])
+		if err != nil && !errors.Is(err, os.ErrNotExist) && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,63 @@
+package ptfs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestSavePerms(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/root/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/root/a", "a")
+	writeFile(t, fs, "/root/sub/b", "b")
+	writeFile(t, fs, "/root/gone", "gone")
+	modes := map[string]os.FileMode{
+		"/root":       0750,
+		"/root/sub":   0700,
+		"/root/a":     0600,
+		"/root/sub/b": 0444,
+		"/root/gone":  0640,
+	}
+	for name, mode := range modes {
+		if err := fs.Chmod(name, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snap, err := fs.SavePerms("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap) != len(modes) {
+		t.Errorf("snapshot has %d entries, want %d", len(snap), len(modes))
+	}
+	for name := range modes {
+		if err := fs.Chmod(name, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fs.Remove("/root/gone"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.RestorePerms(snap); err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range modes {
+		if name != "/root/gone" {
+			assertMode(t, fs, name, mode)
+		}
+	}
+}
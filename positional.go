@@ -0,0 +1,57 @@
+package ptfs
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// probe records whether a file supports an optional operation, probing it
+// once.
+type probe struct {
+	once      sync.Once
+	supported bool
+}
+
+// do returns whether the operation is supported, calling try to probe it on
+// the first call. The operation is considered not supported if try fails
+// with ErrNotSupported or errors.ErrUnsupported.
+func (p *probe) do(try func() error) bool {
+	p.once.Do(func() {
+		p.supported = !isNotSupported(try())
+	})
+	return p.supported
+}
+
+// SupportsReadAt reports whether the file supports ReadAt. The first call
+// probes the file with a zero length ReadAt at offset 0, and the result is
+// cached for later calls. A file is considered not to support ReadAt if the
+// probe fails with ErrNotSupported or errors.ErrUnsupported.
+func (f *File) SupportsReadAt() bool {
+	return f.readAt.do(func() error {
+		_, err := f.f.ReadAt(nil, 0)
+		return err
+	})
+}
+
+// SupportsWriteAt reports whether the file supports WriteAt, probing the
+// file with a zero length WriteAt at offset 0 as SupportsReadAt does. Files
+// opened for reading only never support WriteAt, whatever the probe reports.
+func (f *File) SupportsWriteAt() bool {
+	if f.flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return false
+	}
+	return f.writeAt.do(func() error {
+		_, err := f.f.WriteAt(nil, 0)
+		return err
+	})
+}
+
+// SupportsSeek reports whether the file supports Seek, probing the file with
+// a Seek to its current offset as SupportsReadAt does.
+func (f *File) SupportsSeek() bool {
+	return f.seek.do(func() error {
+		_, err := f.f.Seek(0, io.SeekCurrent)
+		return err
+	})
+}
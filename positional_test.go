@@ -0,0 +1,104 @@
+package ptfs_test
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// streamFS returns files that do not support positional I/O.
+type streamFS struct {
+	absfs.FileSystem
+}
+
+func (fs streamFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return streamFile{f}, nil
+}
+
+type streamFile struct {
+	absfs.File
+}
+
+func (f streamFile) ReadAt(b []byte, off int64) (int, error) {
+	return 0, &os.PathError{Op: "readat", Path: f.Name(), Err: ptfs.ErrNotSupported}
+}
+
+func (f streamFile) WriteAt(b []byte, off int64) (int, error) {
+	return 0, &os.PathError{Op: "writeat", Path: f.Name(), Err: ptfs.ErrNotSupported}
+}
+
+func TestSupportsReadAt(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		fs   absfs.FileSystem
+		want bool
+	}{
+		{"memfs", mfs, true},
+		{"stream", streamFS{mfs}, false},
+	} {
+		fs, err := ptfs.NewFS(test.fs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := fs.Create("/" + test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pf := f.(*ptfs.File)
+		if got := pf.SupportsReadAt(); got != test.want {
+			t.Errorf("%s: SupportsReadAt = %t, want %t", test.name, got, test.want)
+		}
+		if got := pf.SupportsWriteAt(); got != test.want {
+			t.Errorf("%s: SupportsWriteAt = %t, want %t", test.name, got, test.want)
+		}
+		f.Close()
+	}
+}
+
+func TestSupportsWriteAtReadOnly(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/file", "content")
+	f, err := fs.Open("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	pf := f.(*ptfs.File)
+
+	// Probes may run concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pf.SupportsReadAt()
+			pf.SupportsSeek()
+		}()
+	}
+	wg.Wait()
+	if !pf.SupportsReadAt() {
+		t.Error("SupportsReadAt = false on a read only file")
+	}
+	if pf.SupportsWriteAt() {
+		t.Error("SupportsWriteAt = true on a read only file")
+	}
+}
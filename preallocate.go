@@ -0,0 +1,71 @@
+package ptfs
+
+import "github.com/absfs/absfs"
+
+// A Preallocator is a file that can reserve storage space ahead of writes,
+// such as a backend wrapping fallocate(2).
+type Preallocator interface {
+	Preallocate(size int64) error
+}
+
+// Preallocate reserves space for at least size bytes in the file. If the
+// underlying file implements Preallocator the call is passed through, and
+// the file's size is left to the backend. Otherwise Preallocate is emulated
+// by growing the file with Truncate, so the file's size becomes size.
+// Emulation reserves space only to the extent the backend allocates storage
+// for the zero filled region, it does not guarantee contiguous storage.
+// Files already at least size bytes long are unchanged. SupportsPreallocate
+// reports which of the two is used.
+func (f *File) Preallocate(size int64) error {
+	if p, ok := backendFile(f.f).(Preallocator); ok {
+		return p.Preallocate(size)
+	}
+	info, err := f.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() >= size {
+		return nil
+	}
+	return f.f.Truncate(size)
+}
+
+// SupportsPreallocate reports whether the underlying file implements
+// Preallocator, so that Preallocate reserves space natively rather than by
+// growing the file.
+func (f *File) SupportsPreallocate() bool {
+	_, ok := backendFile(f.f).(Preallocator)
+	return ok
+}
+
+// backendFile returns the file of the underlying filesystem wrapped by the
+// layers that the FileSystem adds to file.
+func backendFile(file absfs.File) absfs.File {
+	for {
+		inner, ok := unwrapLayer(file)
+		if !ok {
+			return file
+		}
+		file = inner
+	}
+}
+
+// unwrapLayer returns the file wrapped by file, if file is one of the layers
+// that the FileSystem adds.
+func unwrapLayer(file absfs.File) (absfs.File, bool) {
+	switch l := file.(type) {
+	case *verifyFile:
+		return l.File, true
+	case *eolFile:
+		return l.File, true
+	case *throttledFile:
+		return l.File, true
+	case *writeThrottledFile:
+		return l.File, true
+	case *syncFile:
+		return l.File, true
+	case *pageCacheFile:
+		return l.File, true
+	}
+	return nil, false
+}
@@ -0,0 +1,97 @@
+package ptfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+type preallocFile struct {
+	absfs.File
+	size int64
+}
+
+func (f *preallocFile) Preallocate(size int64) error {
+	f.size = size
+	return nil
+}
+
+type preallocFS struct {
+	absfs.FileSystem
+	files []*preallocFile
+}
+
+func (fs *preallocFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	pf := &preallocFile{File: f}
+	fs.files = append(fs.files, pf)
+	return pf, nil
+}
+
+func TestPreallocate(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("emulated", func(t *testing.T) {
+		fs, err := ptfs.NewFS(mfs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := fs.Create("/emulated")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if f.(*ptfs.File).SupportsPreallocate() {
+			t.Error("SupportsPreallocate = true without a Preallocator")
+		}
+		if err := f.(*ptfs.File).Preallocate(4096); err != nil {
+			t.Fatal(err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != 4096 {
+			t.Errorf("size = %d, want 4096", info.Size())
+		}
+	})
+
+	t.Run("delegated", func(t *testing.T) {
+		pfs := &preallocFS{FileSystem: mfs}
+		// Layers added to files by options do not hide the backend's
+		// Preallocate.
+		fs, err := ptfs.NewFS(pfs, ptfs.WithBandwidth(1<<30), ptfs.WithPeriodicSync(1<<20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := fs.Create("/delegated")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if !f.(*ptfs.File).SupportsPreallocate() {
+			t.Error("SupportsPreallocate = false with a Preallocator")
+		}
+		if err := f.(*ptfs.File).Preallocate(4096); err != nil {
+			t.Fatal(err)
+		}
+		if pfs.files[0].size != 4096 {
+			t.Errorf("backend preallocated %d, want 4096", pfs.files[0].size)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != 0 {
+			t.Errorf("size = %d, want 0", info.Size())
+		}
+	})
+}
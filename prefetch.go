@@ -0,0 +1,210 @@
+package ptfs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// Limits of the access pattern model and cache of NewPrefetchFS.
+const (
+	prefetchWindow     = time.Second // how soon after A an open of B counts as following it
+	prefetchSources    = 1024        // files whose successors are tracked
+	prefetchSuccessors = 8           // successors tracked per file
+	prefetchCached     = 64          // prefetched files held at once
+	prefetchMaxSize    = 1 << 20     // largest file prefetched
+)
+
+// NewPrefetchFS returns a FileSystem that learns which files tend to be
+// opened one after another, and prefetches them. Each time a file B is opened
+// for reading within a second of a file A, the transition from A to B is
+// counted. Once B has followed A at least twice, and more often than all
+// other files together, opening A reads B into memory in the background, and
+// the next open of B is served from memory if B has not changed size or
+// modification time since. Each prefetched file is served once. Opening A
+// never waits for the prefetch.
+//
+// This is a best-effort heuristic for slow backends with repetitive access
+// patterns. The model is bounded: transitions are tracked for at most 1024
+// files with 8 successors each, at most 64 files are held in memory at once,
+// the oldest being dropped to make room for another, and files larger than
+// 1MiB are not prefetched.
+func NewPrefetchFS(fs absfs.FileSystem, opts ...Option) (*FileSystem, error) {
+	return NewFS(&prefetchFS{
+		FileSystem:  fs,
+		transitions: make(map[string]map[string]int),
+		cache:       make(map[string]prefetched),
+		inflight:    make(map[string]bool),
+	}, opts...)
+}
+
+// prefetchFS adapts a filesystem to absfs.FileSystem, prefetching files.
+type prefetchFS struct {
+	absfs.FileSystem
+
+	mu          sync.Mutex
+	last        string
+	lastOpen    time.Time
+	transitions map[string]map[string]int // counts of B following A, by A and B
+	cache       map[string]prefetched
+	inflight    map[string]bool
+	seq         uint64 // count of files prefetched, ordering the cache
+}
+
+type prefetched struct {
+	data []byte
+	info os.FileInfo
+	seq  uint64
+}
+
+// abs returns the absolute path of name, by which files are tracked.
+func (f *prefetchFS) abs(name string) string {
+	if path.IsAbs(name) {
+		return path.Clean(name)
+	}
+	dir, _ := f.FileSystem.Getwd()
+	return path.Join(dir, name)
+}
+
+func (f *prefetchFS) Open(name string) (absfs.File, error) {
+	return f.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (f *prefetchFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return f.FileSystem.OpenFile(name, flag, perm)
+	}
+	key := f.abs(name)
+	next, cached, ok := f.opened(key)
+	if next != "" {
+		go f.prefetch(next)
+	}
+	if ok {
+		if info, err := f.FileSystem.Stat(name); err == nil &&
+			info.Size() == cached.info.Size() && info.ModTime().Equal(cached.info.ModTime()) {
+			return &cachedFile{Reader: bytes.NewReader(cached.data), data: cached.data, name: name, info: cached.info}, nil
+		}
+	}
+	return f.FileSystem.OpenFile(name, flag, perm)
+}
+
+// opened records that key was opened for reading, and returns the file to
+// prefetch, if any, and the prefetched copy of key, if there is one.
+func (f *prefetchFS) opened(key string) (next string, cached prefetched, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if f.last != "" && f.last != key && now.Sub(f.lastOpen) <= prefetchWindow {
+		f.count(f.last, key)
+	}
+	f.last, f.lastOpen = key, now
+
+	if cached, ok = f.cache[key]; ok {
+		delete(f.cache, key)
+	}
+	total, best, most := 0, "", 0
+	for b, n := range f.transitions[key] {
+		total += n
+		if n > most {
+			best, most = b, n
+		}
+	}
+	if most >= 2 && most*2 > total && !f.inflight[best] {
+		if _, done := f.cache[best]; !done {
+			f.inflight[best] = true
+			next = best
+		}
+	}
+	return next, cached, ok
+}
+
+// count records that b was opened shortly after a.
+func (f *prefetchFS) count(a, b string) {
+	succ, ok := f.transitions[a]
+	if !ok {
+		if len(f.transitions) >= prefetchSources {
+			return
+		}
+		succ = make(map[string]int)
+		f.transitions[a] = succ
+	}
+	if _, ok := succ[b]; !ok && len(succ) >= prefetchSuccessors {
+		return
+	}
+	succ[b]++
+}
+
+// prefetch reads the file key into the cache.
+func (f *prefetchFS) prefetch(key string) {
+	defer func() {
+		f.mu.Lock()
+		delete(f.inflight, key)
+		f.mu.Unlock()
+	}()
+	file, err := f.FileSystem.Open(key)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() > prefetchMaxSize {
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, prefetchMaxSize+1))
+	if err != nil || int64(len(data)) != info.Size() {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.cache) >= prefetchCached {
+		f.evict()
+	}
+	f.seq++
+	f.cache[key] = prefetched{data, info, f.seq}
+}
+
+// evict drops the oldest file from the cache.
+func (f *prefetchFS) evict() {
+	oldest, seq := "", uint64(0)
+	for key, p := range f.cache {
+		if oldest == "" || p.seq < seq {
+			oldest, seq = key, p.seq
+		}
+	}
+	delete(f.cache, oldest)
+}
+
+// cachedFile is a read only file served from memory.
+type cachedFile struct {
+	*bytes.Reader
+	data []byte // the content read by Reader
+	name string
+	info os.FileInfo
+}
+
+func (f *cachedFile) Name() string               { return f.name }
+func (f *cachedFile) Stat() (os.FileInfo, error) { return f.info, nil }
+func (f *cachedFile) Sync() error                { return nil }
+func (f *cachedFile) Close() error               { return nil }
+
+func (f *cachedFile) Write(p []byte) (int, error)              { return 0, f.badf("write") }
+func (f *cachedFile) WriteAt(b []byte, off int64) (int, error) { return 0, f.badf("write") }
+func (f *cachedFile) WriteString(s string) (int, error)        { return 0, f.badf("write") }
+func (f *cachedFile) Truncate(size int64) error                { return f.badf("truncate") }
+
+func (f *cachedFile) Readdir(n int) ([]os.FileInfo, error) { return nil, f.notDir() }
+func (f *cachedFile) Readdirnames(n int) ([]string, error) { return nil, f.notDir() }
+
+func (f *cachedFile) badf(op string) error {
+	return &os.PathError{Op: op, Path: f.name, Err: syscall.EBADF}
+}
+
+func (f *cachedFile) notDir() error {
+	return &os.PathError{Op: "readdir", Path: f.name, Err: syscall.ENOTDIR}
+}
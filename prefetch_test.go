@@ -0,0 +1,134 @@
+package ptfs_test
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// openLogFS counts the files opened for reading, by name.
+type openLogFS struct {
+	absfs.FileSystem
+
+	mu    sync.Mutex
+	opens map[string]int
+}
+
+func (fs *openLogFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag == os.O_RDONLY {
+		fs.mu.Lock()
+		fs.opens[name]++
+		fs.mu.Unlock()
+	}
+	return fs.FileSystem.OpenFile(name, flag, perm)
+}
+
+func (fs *openLogFS) Open(name string) (absfs.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (fs *openLogFS) count(name string) int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.opens[name]
+}
+
+func TestNewPrefetchFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/a", "index")
+	writeFile(t, mfs, "/b", "data")
+	backend := &openLogFS{FileSystem: mfs, opens: make(map[string]int)}
+	fs, err := ptfs.NewPrefetchFS(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Train the pattern: /b follows /a.
+	for i := 0; i < 2; i++ {
+		readAll(t, fs, "/a")
+		readAll(t, fs, "/b")
+	}
+
+	// Opening /a reads /b in the background.
+	opens := backend.count("/b")
+	readAll(t, fs, "/a")
+	for deadline := time.Now().Add(time.Second); backend.count("/b") == opens; {
+		if time.Now().After(deadline) {
+			t.Fatal("opening /a did not prefetch /b")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Once the prefetch has completed, /b is served from memory.
+	for deadline := time.Now().Add(time.Second); ; {
+		time.Sleep(10 * time.Millisecond)
+		opens := backend.count("/b")
+		if got := readAll(t, fs, "/b"); got != "data" {
+			t.Fatalf("/b = %q, want %q", got, "data")
+		}
+		if backend.count("/b") == opens {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("/b was never served from the prefetch cache")
+		}
+	}
+}
+
+func TestPrefetchCacheFull(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &openLogFS{FileSystem: mfs, opens: make(map[string]int)}
+	fs, err := ptfs.NewPrefetchFS(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// prefetched opens a then waits for b to be prefetched, after training
+	// the pattern.
+	prefetched := func(a, b string) {
+		t.Helper()
+		writeFile(t, mfs, a, a)
+		writeFile(t, mfs, b, b)
+		for i := 0; i < 2; i++ {
+			readAll(t, fs, a)
+			readAll(t, fs, b)
+		}
+		opens := backend.count(b)
+		readAll(t, fs, a)
+		for deadline := time.Now().Add(time.Second); backend.count(b) == opens; {
+			if time.Now().After(deadline) {
+				t.Fatalf("opening %s did not prefetch %s", a, b)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Fill the cache with prefetched files that are never opened.
+	for i := 0; i < 70; i++ {
+		prefetched(fmt.Sprintf("/a%d", i), fmt.Sprintf("/b%d", i))
+	}
+	// The oldest are dropped to make room for later prefetches.
+	prefetched("/a", "/b")
+	for deadline := time.Now().Add(time.Second); ; {
+		time.Sleep(10 * time.Millisecond)
+		opens := backend.count("/b")
+		readAll(t, fs, "/b")
+		if backend.count("/b") == opens {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("/b was never served from the prefetch cache")
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package ptfs
+
+import (
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewPromotionFS returns a FileSystem serving the files of slow, using fast
+// as a cache. Opening a file for reading opens the copy in fast if there is
+// one, and otherwise first copies, or promotes, the file from slow into
+// fast, creating its parent directories as needed. Concurrent opens of the
+// same file promote it only once. Stat reports the copy in fast if there is
+// one.
+//
+// All modifications are made to slow, and remove any copy of the modified
+// file from fast, and files opened for writing remove it again when closed.
+// Changes made to slow other than through the FileSystem are not noticed
+// while a file is cached.
+func NewPromotionFS(slow, fast absfs.FileSystem, opts ...Option) (*FileSystem, error) {
+	return NewFS(&promotionFS{FileSystem: slow, fast: fast}, opts...)
+}
+
+// promotionFS adapts a slow and a fast filesystem to absfs.FileSystem.
+type promotionFS struct {
+	absfs.FileSystem
+	fast absfs.FileSystem
+
+	locks pathLocks
+}
+
+func (f *promotionFS) Open(name string) (absfs.File, error) {
+	return f.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (f *promotionFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		f.invalidate(name)
+		file, err := f.FileSystem.OpenFile(name, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return &invalidatingFile{file, func() { f.invalidate(name) }}, nil
+	}
+
+	key := f.abs(name)
+	unlock := f.locks.lock(key, true)
+	defer unlock()
+	if file, err := f.fast.Open(key); err == nil {
+		return file, nil
+	}
+	file, err := f.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return file, err
+	}
+	defer file.Close()
+	if err := f.promote(key, file, info); err != nil {
+		return f.FileSystem.Open(name)
+	}
+	return f.fast.Open(key)
+}
+
+// abs returns the absolute path of name, under which it is cached in fast.
+func (f *promotionFS) abs(name string) string {
+	if path.IsAbs(name) {
+		return path.Clean(name)
+	}
+	dir, _ := f.FileSystem.Getwd()
+	return path.Join(dir, name)
+}
+
+// promote copies the open file src, described by info, into fast.
+func (f *promotionFS) promote(name string, src absfs.File, info os.FileInfo) error {
+	if err := f.fast.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	dst, err := f.fast.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.fast.Chtimes(name, info.ModTime(), info.ModTime())
+	}
+	if err != nil {
+		f.fast.Remove(name)
+	}
+	return err
+}
+
+// invalidate removes any cached copy of name from fast.
+func (f *promotionFS) invalidate(name string) {
+	key := f.abs(name)
+	unlock := f.locks.lock(key, true)
+	defer unlock()
+	f.fast.RemoveAll(key)
+}
+
+func (f *promotionFS) Stat(name string) (os.FileInfo, error) {
+	if info, err := f.fast.Stat(f.abs(name)); err == nil && !info.IsDir() {
+		return info, nil
+	}
+	return f.FileSystem.Stat(name)
+}
+
+func (f *promotionFS) Create(name string) (absfs.File, error) {
+	return f.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (f *promotionFS) Remove(name string) error {
+	f.invalidate(name)
+	return f.FileSystem.Remove(name)
+}
+
+func (f *promotionFS) RemoveAll(name string) error {
+	f.invalidate(name)
+	return f.FileSystem.RemoveAll(name)
+}
+
+func (f *promotionFS) Rename(oldname, newname string) error {
+	f.invalidate(oldname)
+	f.invalidate(newname)
+	return f.FileSystem.Rename(oldname, newname)
+}
+
+func (f *promotionFS) Truncate(name string, size int64) error {
+	f.invalidate(name)
+	return f.FileSystem.Truncate(name, size)
+}
+
+func (f *promotionFS) Chmod(name string, mode os.FileMode) error {
+	f.invalidate(name)
+	return f.FileSystem.Chmod(name, mode)
+}
+
+func (f *promotionFS) Chtimes(name string, atime, mtime time.Time) error {
+	f.invalidate(name)
+	return f.FileSystem.Chtimes(name, atime, mtime)
+}
+
+func (f *promotionFS) Chown(name string, uid, gid int) error {
+	f.invalidate(name)
+	return f.FileSystem.Chown(name, uid, gid)
+}
+
+// invalidatingFile calls invalidate after the file is closed.
+type invalidatingFile struct {
+	absfs.File
+	invalidate func()
+}
+
+func (f *invalidatingFile) Close() error {
+	err := f.File.Close()
+	f.invalidate()
+	return err
+}
@@ -0,0 +1,75 @@
+package ptfs_test
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// countingFS counts the files opened for reading.
+type countingFS struct {
+	absfs.FileSystem
+	opens atomic.Int32
+}
+
+func (fs *countingFS) Open(name string) (absfs.File, error) {
+	fs.opens.Add(1)
+	return fs.FileSystem.Open(name)
+}
+
+func TestNewPromotionFS(t *testing.T) {
+	smfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow := &countingFS{FileSystem: smfs}
+	fs, err := ptfs.NewPromotionFS(slow, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/dir/file", "v1")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := fs.Open("/dir/file")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer f.Close()
+			if data, err := io.ReadAll(f); err != nil || string(data) != "v1" {
+				t.Errorf("read %q, %v, want %q", data, err, "v1")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := slow.opens.Load(); n != 1 {
+		t.Fatalf("slow opened %d times, want 1", n)
+	}
+	if got := readAll(t, fast, "/dir/file"); got != "v1" {
+		t.Fatalf("fast holds %q, want %q", got, "v1")
+	}
+
+	writeFile(t, fs, "/dir/file", "v2")
+	if got := readAll(t, fs, "/dir/file"); got != "v2" {
+		t.Fatalf("read after write = %q, want %q", got, "v2")
+	}
+	if n := slow.opens.Load(); n != 2 {
+		t.Fatalf("slow opened %d times after a write, want 2", n)
+	}
+}
@@ -1,13 +1,29 @@
 package ptfs
 
 import (
+	"io"
+	"io/fs"
 	"os"
+	"time"
 
 	"github.com/absfs/absfs"
 )
 
 type File struct {
-	f absfs.File
+	f    absfs.File
+	fs   *FileSystem
+	id   uint64
+	flag int
+
+	// onClose functions are called once after the file is closed.
+	onClose []func() error
+	// onClosed is called after the onClose functions if closing succeeded.
+	onClosed func()
+
+	readAt, writeAt, seek probe
+
+	ioDeadline time.Duration
+	pending    chan struct{} // closed when an abandoned operation finishes
 }
 
 func (f *File) Name() string {
@@ -15,23 +31,61 @@ func (f *File) Name() string {
 }
 
 func (f *File) Read(p []byte) (int, error) {
+	if f.ioDeadline > 0 {
+		return f.readDeadline("read", p, f.f.Read)
+	}
 	return f.f.Read(p)
 }
 
 func (f *File) ReadAt(b []byte, off int64) (n int, err error) {
+	if f.ioDeadline > 0 {
+		return f.readDeadline("read", b, func(b []byte) (int, error) {
+			return f.f.ReadAt(b, off)
+		})
+	}
 	return f.f.ReadAt(b, off)
 }
 
 func (f *File) Write(p []byte) (int, error) {
+	if f.ioDeadline > 0 {
+		return f.writeDeadline("write", p, f.f.Write)
+	}
 	return f.f.Write(p)
 }
 
 func (f *File) WriteAt(b []byte, off int64) (n int, err error) {
+	if f.ioDeadline > 0 {
+		return f.writeDeadline("write", b, func(b []byte) (int, error) {
+			return f.f.WriteAt(b, off)
+		})
+	}
 	return f.f.WriteAt(b, off)
 }
 
+// Close closes the file. If an operation abandoned after exceeding the I/O
+// deadline is still running, Close first waits for it to finish, so that the
+// underlying file is not closed while in use.
 func (f *File) Close() error {
-	return f.f.Close()
+	if f.pending != nil {
+		<-f.pending
+		f.pending = nil
+	}
+	err := f.f.Close()
+	if f.fs != nil {
+		f.fs.handles.remove(f.id)
+	}
+	hooks := f.onClose
+	f.onClose = nil
+	for _, hook := range hooks {
+		if herr := hook(); err == nil {
+			err = herr
+		}
+	}
+	if onClosed := f.onClosed; onClosed != nil && err == nil {
+		f.onClosed = nil
+		onClosed()
+	}
+	return err
 }
 
 func (f *File) Seek(offset int64, whence int) (ret int64, err error) {
@@ -54,10 +108,43 @@ func (f *File) Readdirnames(n int) ([]string, error) {
 	return f.f.Readdirnames(n)
 }
 
-func (f *File) Truncate(size int64) error {
-	return f.f.Truncate(size)
+// ReadDir reads the contents of the directory and returns up to n entries as
+// fs.ReadDirFile does, so that File can back an io/fs directory. If n > 0,
+// ReadDir returns at most n entries, and io.EOF at the end of the directory.
+// If n <= 0, it returns all remaining entries and a nil error unless reading
+// fails. The "." and ".." entries reported by some filesystems are omitted.
+func (f *File) ReadDir(n int) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	dotsOnly := false
+	for {
+		infos, err := f.f.Readdir(n - len(entries))
+		added := 0
+		for _, info := range infos {
+			if info.Name() != "." && info.Name() != ".." {
+				entries = append(entries, fs.FileInfoToDirEntry(info))
+				added++
+			}
+		}
+		if n <= 0 || err != nil || len(entries) == n {
+			return entries, err
+		}
+		if added == 0 {
+			// Some filesystems end a directory with no entries and no
+			// error, or keep returning "." and "..".
+			if len(infos) == 0 || dotsOnly {
+				if len(entries) == 0 {
+					return nil, io.EOF
+				}
+				return entries, nil
+			}
+			dotsOnly = true
+		}
+	}
 }
 
 func (f *File) WriteString(s string) (n int, err error) {
+	if f.ioDeadline > 0 {
+		return f.deadlineIO("write", []byte(s), f.f.Write)
+	}
 	return f.f.WriteString(s)
 }
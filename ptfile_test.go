@@ -0,0 +1,171 @@
+package ptfs_test
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestFileReadDir(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pfs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := pfs.Mkdir("/dir/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"/dir/a", "/dir/b"} {
+		writeFile(t, pfs, name, name)
+	}
+
+	open := func() fs.ReadDirFile {
+		f, err := pfs.Open("/dir")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f.(*ptfs.File)
+	}
+
+	d := open()
+	var names []string
+	for {
+		entries, err := d.ReadDir(2)
+		if len(entries) > 2 {
+			t.Fatalf("ReadDir(2) returned %d entries", len(entries))
+		}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(names) != 3 {
+		t.Fatalf("paginated ReadDir returned %v, want 3 entries", names)
+	}
+
+	entries, err := open().ReadDir(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ReadDir(-1) returned %d entries, want 3", len(entries))
+	}
+	for _, e := range entries {
+		if want := e.Name() == "sub"; e.IsDir() != want {
+			t.Errorf("%s: IsDir() = %v, want %v", e.Name(), e.IsDir(), want)
+		}
+	}
+}
+
+// noEOFFS opens directories that end with no entries and no error, rather
+// than io.EOF, after first reporting "." and ".." if dots is set. With
+// dots, the end of the directory repeats "." and ".." instead.
+type noEOFFS struct {
+	absfs.FileSystem
+	dots bool
+}
+
+func (fs noEOFFS) Open(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &noEOFDir{File: f, dots: fs.dots}, nil
+}
+
+type noEOFDir struct {
+	absfs.File
+	dots    bool
+	infos   []os.FileInfo
+	started bool
+}
+
+func (d *noEOFDir) Readdir(n int) ([]os.FileInfo, error) {
+	if !d.started {
+		d.started = true
+		infos, err := d.File.Readdir(-1)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if info.Name() != "." && info.Name() != ".." {
+				d.infos = append(d.infos, info)
+			}
+		}
+	}
+	if len(d.infos) == 0 {
+		if d.dots {
+			info, err := d.File.Stat()
+			if err != nil {
+				return nil, err
+			}
+			return []os.FileInfo{namedInfo{info, "."}, namedInfo{info, ".."}}, nil
+		}
+		return nil, nil
+	}
+	n = min(n, len(d.infos))
+	infos := d.infos[:n]
+	d.infos = d.infos[n:]
+	return infos, nil
+}
+
+type namedInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (i namedInfo) Name() string { return i.name }
+
+func TestFileReadDirNoEOF(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"/dir/a", "/dir/b", "/dir/c"} {
+		writeFile(t, mfs, name, name)
+	}
+
+	for _, dots := range []bool{false, true} {
+		pfs, err := ptfs.NewFS(noEOFFS{mfs, dots})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := pfs.Open("/dir")
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := f.(*ptfs.File)
+		entries, err := d.ReadDir(2)
+		if len(entries) != 2 || err != nil {
+			t.Fatalf("dots %v: first ReadDir(2) = %d entries, %v", dots, len(entries), err)
+		}
+		entries, err = d.ReadDir(2)
+		if len(entries) != 1 || err != nil {
+			t.Fatalf("dots %v: second ReadDir(2) = %d entries, %v", dots, len(entries), err)
+		}
+		if entries, err = d.ReadDir(2); len(entries) != 0 || err != io.EOF {
+			t.Fatalf("dots %v: ReadDir(2) at the end = %d entries, %v, want io.EOF", dots, len(entries), err)
+		}
+		f.Close()
+	}
+}
@@ -1,6 +1,7 @@
 package ptfs
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -17,9 +18,10 @@ func NewFiler(fs absfs.Filer) (*Filer, error) {
 
 // Filer interface
 
-// OpenFile opens a file using the given flags and the given mode.
+// OpenFile opens a file using the given flags and the given mode. If flag
+// includes O_DIRECTORY, opening anything other than a directory fails.
 func (f *Filer) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
-	return f.fs.OpenFile(name, flag, perm)
+	return openFile(f.fs, name, flag, perm)
 }
 
 // Mkdir creates a directory in the filesystem, return an error if any
@@ -60,54 +62,347 @@ func (f *Filer) Chown(name string, uid, gid int) error {
 }
 
 type FileSystem struct {
-	fs absfs.FileSystem
+	fs   absfs.FileSystem
+	caps capabilities
+	name string
+
+	eol      EOLMode
+	eolMatch func(name string) bool
+
+	handles handleRegistry
+	stacks  bool
+
+	allow   Allowlist
+	denied  func(op, name string)
+	denials denialLog
+
+	idempotentRemove bool
+
+	locks pathLocks
+
+	writeLock  bool
+	writeLocks pathLocks
+
+	retries    int
+	retryDelay time.Duration
+
+	fixedTime *time.Time
+
+	validNames []func(name string) bool
+
+	errs *errorLog
+
+	rewrites []pathRewrite
+
+	etags etagCache
+
+	maxDepth int
+
+	ioDeadline time.Duration
+
+	sparseBlock int
+
+	identity *identity
+
+	latency   time.Duration
+	bandwidth int64
+
+	metrics *Metrics
+
+	accesses *AccessMap
+
+	canonicalMode bool
+
+	syncEvery int64
+
+	freeze     freezeGate
+	freezeMode FreezeMode
+
+	strictMmap bool
+
+	verifyWrites bool
+
+	aclChmod bool
+
+	binarySample int
+
+	identityACL map[string]Allowlist
+
+	syncCompare SyncCompare
+
+	writeTokens *tokenBucket
+
+	onEvent     func(Event)
+	eventWindow time.Duration
+	debounce    debouncer
+
+	createParents bool
+	parentPerm    os.FileMode
+
+	overrides overrides
+
+	modTimeLoc         *time.Location
+	chtimesSkipMissing bool
+	categorizeErrors   bool
+
+	pageCache *pageCache
+}
+
+// An Option configures optional behavior of a FileSystem.
+type Option func(*FileSystem) error
+
+// NewFS returns a FileSystem that passes all operations through to fs,
+// modified only by the given options.
+func NewFS(fs absfs.FileSystem, opts ...Option) (*FileSystem, error) {
+	f := &FileSystem{fs: fs, caps: probeCapabilities(fs)}
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
 }
 
-func NewFS(fs absfs.FileSystem) (*FileSystem, error) {
-	return &FileSystem{fs}, nil
+// path checks that op may be performed on the named file, and returns the
+// name to pass to the underlying filesystem.
+func (f *FileSystem) path(op, name string) (string, error) {
+	if err := f.checkFrozen(op, name); err != nil {
+		return "", err
+	}
+	if err := f.checkAllowed(op, name); err != nil {
+		return "", err
+	}
+	if f.accesses != nil {
+		switch op {
+		case "Open", "Stat", "Lstat":
+			f.accesses.record(name)
+		}
+	}
+	return f.mapPath(op, name)
+}
+
+// file applies any file level options to a file opened through the
+// FileSystem, and registers it as an open handle.
+func (f *FileSystem) file(name string, flag int, file absfs.File) absfs.File {
+	if f.pageCache != nil && flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		file = f.pageCache.cacheFile(name, file)
+	}
+	var vf *verifyFile
+	if f.verifyWrites && flag&os.O_TRUNC != 0 && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		vf = newVerifyFile(file)
+		file = vf
+	}
+	if f.eol != 0 && (f.eolMatch == nil || f.eolMatch(name)) {
+		file = newEOLFile(file, f.eol)
+	}
+	if f.bandwidth > 0 {
+		file = &throttledFile{file, f.bandwidth}
+	}
+	if f.writeTokens != nil && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		file = &writeThrottledFile{file, f.writeTokens}
+	}
+	if f.syncEvery > 0 && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		file = &syncFile{File: file, every: f.syncEvery}
+	}
+	pf := &File{f: file, fs: f, id: f.handles.add(name, flag, f.stacks), flag: flag, ioDeadline: f.ioDeadline}
+	if f.fixedTime != nil && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		pf.onClose = append(pf.onClose, func() error {
+			return f.Chtimes(name, *f.fixedTime, *f.fixedTime)
+		})
+	}
+	if vf != nil {
+		pf.onClose = append(pf.onClose, func() error {
+			return f.verify(name, vf)
+		})
+	}
+	if f.onEvent != nil && flag&(os.O_WRONLY|os.O_RDWR|os.O_TRUNC|os.O_APPEND) != 0 {
+		pf.onClosed = func() {
+			f.emit(Event{Op: "Write", Path: name})
+		}
+	}
+	return pf
 }
 
 // FileSystem interface
 
-// OpenFile opens a file using the given flags and the given mode.
+// OpenFile opens a file using the given flags and the given mode. If flag
+// includes O_DIRECTORY, opening anything other than a directory fails.
 func (f *FileSystem) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
-	return f.fs.OpenFile(name, flag, perm)
+	return f.openFileContext(context.Background(), name, flag, perm)
+}
+
+// openFileContext is OpenFile, waiting for a write token, if it takes one,
+// until ctx is done.
+func (f *FileSystem) openFileContext(ctx context.Context, name string, flag int, perm os.FileMode) (file absfs.File, err error) {
+	defer f.done("OpenFile", time.Now(), &err)
+	if flag&os.O_CREATE != 0 {
+		if err := f.checkCreate("OpenFile", name); err != nil {
+			return nil, err
+		}
+	}
+	p, err := f.path("OpenFile", name)
+	if err != nil {
+		return nil, err
+	}
+	if flag&(os.O_CREATE|os.O_TRUNC) != 0 {
+		if err := f.writeTokens.take(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		if f.accesses != nil {
+			f.accesses.record(name)
+		}
+		if file, ok := f.openOverride(name); ok {
+			return file, nil
+		}
+	}
+	unlock := f.lockOpen(p, flag)
+	file, err = openFile(f.fs, p, flag, perm)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlockOnClose(f.file(name, flag, file), unlock), nil
 }
 
 // Mkdir creates a directory in the filesystem, return an error if any
 // happens.
 func (f *FileSystem) Mkdir(name string, perm os.FileMode) error {
+	return f.mkdirContext(context.Background(), name, perm)
+}
+
+func (f *FileSystem) mkdirContext(ctx context.Context, name string, perm os.FileMode) (err error) {
+	defer f.done("Mkdir", time.Now(), &err)
+	defer f.notify(Event{Op: "Mkdir", Path: name}, &err)
+	if err := f.checkCreate("Mkdir", name); err != nil {
+		return err
+	}
+	if name, err = f.path("Mkdir", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
 	return f.fs.Mkdir(name, perm)
 }
 
 // Remove removes a file identified by name, returning an error, if any
 // happens.
 func (f *FileSystem) Remove(name string) error {
-	return f.fs.Remove(name)
+	return f.removeContext(context.Background(), name)
+}
+
+func (f *FileSystem) removeContext(ctx context.Context, name string) (err error) {
+	defer f.done("Remove", time.Now(), &err)
+	defer f.notify(Event{Op: "Remove", Path: name}, &err)
+	if name, err = f.path("Remove", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
+	defer f.lockWrite(name)()
+	return f.removed(f.fs.Remove(name))
 }
 
 func (f *FileSystem) Rename(oldname, newname string) error {
+	return f.renameContext(context.Background(), oldname, newname)
+}
+
+func (f *FileSystem) renameContext(ctx context.Context, oldname, newname string) (err error) {
+	defer f.done("Rename", time.Now(), &err)
+	defer f.notify(Event{Op: "Rename", Path: newname, OldPath: oldname}, &err)
+	if err := f.checkCreate("Rename", newname); err != nil {
+		return err
+	}
+	if oldname, err = f.path("Rename", oldname); err != nil {
+		return err
+	}
+	if newname, err = f.path("Rename", newname); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
+	defer f.lockWrite2(oldname, newname)()
 	return f.fs.Rename(oldname, newname)
 }
 
 // Stat returns the FileInfo structure describing file. If there is an error,
 // it will be of type *PathError.
-func (f *FileSystem) Stat(name string) (os.FileInfo, error) {
-	return f.fs.Stat(name)
+func (f *FileSystem) Stat(name string) (info os.FileInfo, err error) {
+	defer f.done("Stat", time.Now(), &err)
+	p, err := f.path("Stat", name)
+	if err != nil {
+		return nil, err
+	}
+	if info, ok := f.overrides.stat(name); ok {
+		return f.inLocation(info), nil
+	}
+	err = f.retry(func() (err error) {
+		info, err = f.fs.Stat(p)
+		return err
+	})
+	return f.inLocation(f.canonical(info)), err
 }
 
 //Chmod changes the mode of the named file to mode.
 func (f *FileSystem) Chmod(name string, mode os.FileMode) error {
+	return f.chmodContext(context.Background(), name, mode)
+}
+
+func (f *FileSystem) chmodContext(ctx context.Context, name string, mode os.FileMode) (err error) {
+	defer f.done("Chmod", time.Now(), &err)
+	defer f.notify(Event{Op: "Chmod", Path: name}, &err)
+	if name, err = f.path("Chmod", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
+	defer f.lockWrite(name)()
+	if f.aclChmod && f.caps.aclSetter != nil {
+		return f.caps.aclSetter.SetACL(name, ModeACL(mode))
+	}
 	return f.fs.Chmod(name, mode)
 }
 
 //Chtimes changes the access and modification times of the named file
 func (f *FileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return f.chtimesContext(context.Background(), name, atime, mtime)
+}
+
+func (f *FileSystem) chtimesContext(ctx context.Context, name string, atime, mtime time.Time) (err error) {
+	defer f.done("Chtimes", time.Now(), &err)
+	defer f.notify(Event{Op: "Chtimes", Path: name}, &err)
+	if name, err = f.path("Chtimes", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
+	if f.fixedTime != nil {
+		atime, mtime = *f.fixedTime, *f.fixedTime
+	}
+	defer f.lockWrite(name)()
 	return f.fs.Chtimes(name, atime, mtime)
 }
 
 //Chown changes the owner and group ids of the named file
 func (f *FileSystem) Chown(name string, uid, gid int) error {
+	return f.chownContext(context.Background(), name, uid, gid)
+}
+
+func (f *FileSystem) chownContext(ctx context.Context, name string, uid, gid int) (err error) {
+	defer f.done("Chown", time.Now(), &err)
+	defer f.notify(Event{Op: "Chown", Path: name}, &err)
+	if name, err = f.path("Chown", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
 	return f.fs.Chown(name, uid, gid)
 }
 
@@ -119,133 +414,237 @@ func (f *FileSystem) ListSeparator() uint8 {
 	return f.fs.ListSeparator()
 }
 
-func (f *FileSystem) Chdir(dir string) error {
+func (f *FileSystem) Chdir(dir string) (err error) {
+	defer f.done("Chdir", time.Now(), &err)
+	if dir, err = f.path("Chdir", dir); err != nil {
+		return err
+	}
 	return f.fs.Chdir(dir)
 }
 
 func (f *FileSystem) Getwd() (dir string, err error) {
-	return f.fs.Getwd()
+	defer f.done("Getwd", time.Now(), &err)
+	if dir, err = f.fs.Getwd(); err != nil {
+		return "", err
+	}
+	return f.virtualPath(dir), nil
 }
 
 func (f *FileSystem) TempDir() string {
 	return f.fs.TempDir()
 }
 
-func (f *FileSystem) Open(name string) (absfs.File, error) {
-	return f.fs.Open(name)
+func (f *FileSystem) Open(name string) (file absfs.File, err error) {
+	defer f.done("Open", time.Now(), &err)
+	p, err := f.path("Open", name)
+	if err != nil {
+		return nil, err
+	}
+	if file, ok := f.openOverride(name); ok {
+		return file, nil
+	}
+	err = f.retry(func() (err error) {
+		file, err = f.fs.Open(p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f.file(name, os.O_RDONLY, file), nil
 }
 
 func (f *FileSystem) Create(name string) (absfs.File, error) {
-	return f.fs.Create(name)
+	return f.createContext(context.Background(), name)
+}
+
+func (f *FileSystem) createContext(ctx context.Context, name string) (file absfs.File, err error) {
+	defer f.done("Create", time.Now(), &err)
+	if err := f.checkCreate("Create", name); err != nil {
+		return nil, err
+	}
+	p, err := f.path("Create", name)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return nil, err
+	}
+	unlock := f.lockWrite(p)
+	file, err = f.fs.Create(p)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlockOnClose(f.file(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, file), unlock), nil
 }
 
 func (f *FileSystem) MkdirAll(name string, perm os.FileMode) error {
+	return f.mkdirAllContext(context.Background(), name, perm)
+}
+
+func (f *FileSystem) mkdirAllContext(ctx context.Context, name string, perm os.FileMode) (err error) {
+	defer f.done("MkdirAll", time.Now(), &err)
+	defer f.notify(Event{Op: "MkdirAll", Path: name}, &err)
+	if err := f.checkCreate("MkdirAll", name); err != nil {
+		return err
+	}
+	if f.maxDepth > 0 {
+		if err := f.checkMkdirDepth(name); err != nil {
+			return err
+		}
+	}
+	if name, err = f.path("MkdirAll", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
 	return f.fs.MkdirAll(name, perm)
 }
 
-func (f *FileSystem) RemoveAll(path string) (err error) {
-	return f.fs.RemoveAll(path)
+func (f *FileSystem) RemoveAll(path string) error {
+	return f.removeAllContext(context.Background(), path)
 }
 
+func (f *FileSystem) removeAllContext(ctx context.Context, path string) (err error) {
+	defer f.done("RemoveAll", time.Now(), &err)
+	defer f.notify(Event{Op: "RemoveAll", Path: path}, &err)
+	p, err := f.path("RemoveAll", path)
+	if err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
+	defer f.lockWrite(p)()
+	if f.maxDepth > 0 {
+		return f.removed(f.removeAllLimited(path))
+	}
+	return f.removed(f.fs.RemoveAll(p))
+}
+
+// Truncate changes the size of the named file. Growing the file extends it
+// with zeros even if the underlying filesystem does not.
 func (f *FileSystem) Truncate(name string, size int64) error {
-	return f.fs.Truncate(name, size)
+	return f.truncateContext(context.Background(), name, size)
+}
+
+func (f *FileSystem) truncateContext(ctx context.Context, name string, size int64) (err error) {
+	defer f.done("Truncate", time.Now(), &err)
+	defer f.notify(Event{Op: "Truncate", Path: name}, &err)
+	if name, err = f.path("Truncate", name); err != nil {
+		return err
+	}
+	if err := f.writeTokens.take(ctx); err != nil {
+		return err
+	}
+	defer f.lockWrite(name)()
+	return f.truncate(name, size)
 }
 
 type SymlinkFileSystem struct {
+	fs  *FileSystem
 	sfs absfs.SymlinkFileSystem
 }
 
-func NewSymlinkFS(fs absfs.SymlinkFileSystem) (*SymlinkFileSystem, error) {
-	return &SymlinkFileSystem{fs}, nil
+// NewSymlinkFS returns a SymlinkFileSystem that passes all operations
+// through to fs, modified only by the given options.
+func NewSymlinkFS(fs absfs.SymlinkFileSystem, opts ...Option) (*SymlinkFileSystem, error) {
+	pfs, err := NewFS(fs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SymlinkFileSystem{pfs, fs}, nil
 }
 
-// OpenFile opens a file using the given flags and the given mode.
+// OpenFile opens a file using the given flags and the given mode. If flag
+// includes O_DIRECTORY, opening anything other than a directory fails.
 func (f *SymlinkFileSystem) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
-	return f.sfs.OpenFile(name, flag, perm)
+	return f.fs.OpenFile(name, flag, perm)
 }
 
 // Mkdir creates a directory in the filesystem, return an error if any
 // happens.
 func (f *SymlinkFileSystem) Mkdir(name string, perm os.FileMode) error {
-	return f.sfs.Mkdir(name, perm)
+	return f.fs.Mkdir(name, perm)
 }
 
 // Remove removes a file identified by name, returning an error, if any
 // happens.
 func (f *SymlinkFileSystem) Remove(name string) error {
-	return f.sfs.Remove(name)
+	return f.fs.Remove(name)
 }
 
 func (f *SymlinkFileSystem) Rename(oldname, newname string) error {
-	return f.sfs.Rename(oldname, newname)
+	return f.fs.Rename(oldname, newname)
 }
 
 // Stat returns the FileInfo structure describing file. If there is an error,
 // it will be of type *PathError.
 func (f *SymlinkFileSystem) Stat(name string) (os.FileInfo, error) {
-	return f.sfs.Stat(name)
+	return f.fs.Stat(name)
 }
 
 //Chmod changes the mode of the named file to mode.
 func (f *SymlinkFileSystem) Chmod(name string, mode os.FileMode) error {
-	return f.sfs.Chmod(name, mode)
+	return f.fs.Chmod(name, mode)
 }
 
 //Chtimes changes the access and modification times of the named file
 func (f *SymlinkFileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
-	return f.sfs.Chtimes(name, atime, mtime)
+	return f.fs.Chtimes(name, atime, mtime)
 }
 
 //Chown changes the owner and group ids of the named file
 func (f *SymlinkFileSystem) Chown(name string, uid, gid int) error {
-	return f.sfs.Chown(name, uid, gid)
+	return f.fs.Chown(name, uid, gid)
 }
 
 func (f *SymlinkFileSystem) Separator() uint8 {
-	return f.sfs.Separator()
+	return f.fs.Separator()
 }
 
 func (f *SymlinkFileSystem) ListSeparator() uint8 {
-	return f.sfs.ListSeparator()
+	return f.fs.ListSeparator()
 }
 
 func (f *SymlinkFileSystem) Chdir(dir string) error {
-	return f.sfs.Chdir(dir)
+	return f.fs.Chdir(dir)
 }
 
 func (f *SymlinkFileSystem) Getwd() (dir string, err error) {
-	return f.sfs.Getwd()
+	return f.fs.Getwd()
 }
 
 func (f *SymlinkFileSystem) TempDir() string {
-	return f.sfs.TempDir()
+	return f.fs.TempDir()
 }
 
 func (f *SymlinkFileSystem) Open(name string) (absfs.File, error) {
-	return f.sfs.Open(name)
+	return f.fs.Open(name)
 }
 
 func (f *SymlinkFileSystem) Create(name string) (absfs.File, error) {
-	return f.sfs.Create(name)
+	return f.fs.Create(name)
 }
 
 func (f *SymlinkFileSystem) MkdirAll(name string, perm os.FileMode) error {
-	return f.sfs.MkdirAll(name, perm)
+	return f.fs.MkdirAll(name, perm)
 }
 
 func (f *SymlinkFileSystem) RemoveAll(path string) (err error) {
-	return f.sfs.RemoveAll(path)
+	return f.fs.RemoveAll(path)
 }
 
 func (f *SymlinkFileSystem) Truncate(name string, size int64) error {
-	return f.sfs.Truncate(name, size)
+	return f.fs.Truncate(name, size)
 }
 
 // Lstat returns a FileInfo describing the named file. If the file is a
 // symbolic link, the returned FileInfo describes the symbolic link. Lstat
 // makes no attempt to follow the link. If there is an error, it will be of type *PathError.
 func (f *SymlinkFileSystem) Lstat(name string) (os.FileInfo, error) {
-	return f.sfs.Lstat(name)
+	return f.fs.lstat(name)
 }
 
 // Lchown changes the numeric uid and gid of the named file. If the file is a
@@ -254,18 +653,39 @@ func (f *SymlinkFileSystem) Lstat(name string) (os.FileInfo, error) {
 //
 // On Windows, it always returns the syscall.EWINDOWS error, wrapped in
 // *PathError.
-func (f *SymlinkFileSystem) Lchown(name string, uid, gid int) error {
+func (f *SymlinkFileSystem) Lchown(name string, uid, gid int) (err error) {
+	defer f.fs.done("Lchown", time.Now(), &err)
+	if name, err = f.fs.path("Lchown", name); err != nil {
+		return err
+	}
+	f.fs.writeTokens.wait()
 	return f.sfs.Lchown(name, uid, gid)
 }
 
 // Readlink returns the destination of the named symbolic link. If there is an
 // error, it will be of type *PathError.
-func (f *SymlinkFileSystem) Readlink(name string) (string, error) {
+func (f *SymlinkFileSystem) Readlink(name string) (link string, err error) {
+	defer f.fs.done("Readlink", time.Now(), &err)
+	if name, err = f.fs.path("Readlink", name); err != nil {
+		return "", err
+	}
 	return f.sfs.Readlink(name)
 }
 
 // Symlink creates newname as a symbolic link to oldname. If there is an
 // error, it will be of type *LinkError.
-func (f *SymlinkFileSystem) Symlink(oldname, newname string) error {
+func (f *SymlinkFileSystem) Symlink(oldname, newname string) (err error) {
+	defer f.fs.done("Symlink", time.Now(), &err)
+	defer f.fs.notify(Event{Op: "Symlink", Path: newname}, &err)
+	if err := f.fs.checkCreate("Symlink", newname); err != nil {
+		return err
+	}
+	if oldname, err = f.fs.mapPath("Symlink", oldname); err != nil {
+		return err
+	}
+	if newname, err = f.fs.path("Symlink", newname); err != nil {
+		return err
+	}
+	f.fs.writeTokens.wait()
 	return f.sfs.Symlink(oldname, newname)
 }
@@ -1,6 +1,8 @@
 package ptfs_test
 
 import (
+	"io"
+	"os"
 	"testing"
 
 	"github.com/absfs/absfs"
@@ -21,3 +23,63 @@ func TestPtfs(t *testing.T) {
 	fs = pfs
 	_ = fs
 }
+
+type opener interface {
+	Open(name string) (absfs.File, error)
+}
+
+func readAll(t *testing.T, fs opener, name string) string {
+	t.Helper()
+	f, err := fs.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func assertMode(t *testing.T, fs *ptfs.FileSystem, name string, want os.FileMode) {
+	t.Helper()
+	info, err := fs.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != want {
+		t.Errorf("%s: mode = %o, want %o", name, got, want)
+	}
+}
+
+func writeFile(t *testing.T, fs absfs.FileSystem, name, content string) {
+	t.Helper()
+	f, err := fs.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readDirNames returns the names in a directory, excluding "." and "..".
+func readDirNames(fs absfs.FileSystem, name string) ([]string, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	all, err := f.Readdirnames(-1)
+	var names []string
+	for _, n := range all {
+		if n != "." && n != ".." {
+			names = append(names, n)
+		}
+	}
+	return names, err
+}
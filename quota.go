@@ -0,0 +1,283 @@
+package ptfs
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/absfs/absfs"
+)
+
+// NewDirQuotaFS returns a FileSystem that limits the total size of the
+// regular files below each directory in quotas to the number of bytes it is
+// keyed to. Writes, truncates and renames that would take a directory over
+// its quota fail with an error wrapping syscall.EDQUOT, and leave the file
+// unchanged. Nested quotas all apply.
+//
+// The usage of a directory is computed by walking it the first time a file
+// below it is modified, and then kept up to date as files are written,
+// truncated and removed. RemoveAll and Rename cause the usage of the
+// directories they affect to be computed again on their next use. Changes
+// made other than through the FileSystem are not noticed until then.
+func NewDirQuotaFS(fs absfs.FileSystem, quotas map[string]int64, opts ...Option) (*FileSystem, error) {
+	q := &quotaFS{FileSystem: fs, limits: make(map[string]int64), usage: make(map[string]int64)}
+	for dir, limit := range quotas {
+		if !path.IsAbs(dir) {
+			return nil, &os.PathError{Op: "quota", Path: dir, Err: syscall.EINVAL}
+		}
+		q.limits[path.Clean(dir)] = limit
+	}
+	return NewFS(q, opts...)
+}
+
+// quotaFS adapts a filesystem to absfs.FileSystem, enforcing per directory
+// quotas.
+type quotaFS struct {
+	absfs.FileSystem
+	limits map[string]int64
+
+	mu    sync.Mutex
+	usage map[string]int64 // bytes used below each quota directory, if known
+}
+
+// abs returns the absolute path of name.
+func (q *quotaFS) abs(name string) string {
+	if path.IsAbs(name) {
+		return path.Clean(name)
+	}
+	dir, _ := q.FileSystem.Getwd()
+	return path.Join(dir, name)
+}
+
+// quotas returns the quota directories name is below.
+func (q *quotaFS) quotas(name string) []string {
+	var dirs []string
+	for dir := range q.limits {
+		if under(name, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// under reports whether name is dir or below it.
+func under(name, dir string) bool {
+	return name == dir || dir == "/" || strings.HasPrefix(name, dir+"/")
+}
+
+// charge adds delta bytes to the usage of every quota directory name is
+// below. A positive delta that would exceed a quota is not charged, and an
+// error wrapping syscall.EDQUOT is returned instead. The usage of a directory
+// is computed first if it is not known, so a zero delta can be used to make
+// sure the usage is known before name is changed.
+func (q *quotaFS) charge(op, name string, delta int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.chargeDirs(op, name, q.quotas(name), delta)
+}
+
+func (q *quotaFS) chargeDirs(op, name string, dirs []string, delta int64) error {
+	for _, dir := range dirs {
+		used, ok := q.usage[dir]
+		if !ok {
+			used = q.du(dir)
+			q.usage[dir] = used
+		}
+		if delta > 0 && used+delta > q.limits[dir] {
+			return &os.PathError{Op: op, Path: name, Err: syscall.EDQUOT}
+		}
+	}
+	for _, dir := range dirs {
+		q.usage[dir] += delta
+	}
+	return nil
+}
+
+// du returns the total size of the regular files below dir.
+func (q *quotaFS) du(dir string) int64 {
+	var total int64
+	fs, err := NewFS(q.FileSystem)
+	if err != nil {
+		return 0
+	}
+	fs.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// forget discards the usage of the quota directories affected by a change
+// to name, so that it is computed again.
+func (q *quotaFS) forget(name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for dir := range q.usage {
+		if under(name, dir) || under(dir, name) {
+			delete(q.usage, dir)
+		}
+	}
+}
+
+// size returns the size of the regular file name, or 0.
+func (q *quotaFS) size(name string) int64 {
+	info, err := q.FileSystem.Stat(name)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
+
+func (q *quotaFS) Create(name string) (absfs.File, error) {
+	return q.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+func (q *quotaFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_TRUNC|os.O_APPEND) == 0 {
+		return q.FileSystem.OpenFile(name, flag, perm)
+	}
+	key := q.abs(name)
+	var old int64
+	if flag&os.O_TRUNC != 0 {
+		q.charge("open", key, 0)
+		old = q.size(name)
+	}
+	file, err := q.FileSystem.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	q.charge("open", key, -old)
+	return &quotaFile{File: file, q: q, key: key, append: flag&os.O_APPEND != 0}, nil
+}
+
+func (q *quotaFS) Truncate(name string, size int64) error {
+	key := q.abs(name)
+	old := q.size(name)
+	if err := q.charge("truncate", key, size-old); err != nil {
+		return err
+	}
+	err := q.FileSystem.Truncate(name, size)
+	q.charge("truncate", key, q.size(name)-size)
+	return err
+}
+
+func (q *quotaFS) Remove(name string) error {
+	key := q.abs(name)
+	q.charge("remove", key, 0)
+	size := q.size(name)
+	if err := q.FileSystem.Remove(name); err != nil {
+		return err
+	}
+	return q.charge("remove", key, -size)
+}
+
+func (q *quotaFS) RemoveAll(name string) error {
+	defer q.forget(q.abs(name))
+	return q.FileSystem.RemoveAll(name)
+}
+
+func (q *quotaFS) Rename(oldname, newname string) error {
+	oldkey, newkey := q.abs(oldname), q.abs(newname)
+	q.mu.Lock()
+	var dirs []string
+	for _, dir := range q.quotas(newkey) {
+		if !under(oldkey, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	// Check that the moved files fit in the quotas they are moved into. The
+	// usage is computed again after the rename.
+	err := q.chargeDirs("rename", newkey, dirs, q.du(oldkey))
+	q.mu.Unlock()
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EDQUOT}
+	}
+	defer q.forget(oldkey)
+	defer q.forget(newkey)
+	return q.FileSystem.Rename(oldname, newname)
+}
+
+// quotaFile charges writes that grow a file against the quotas of the
+// directories it is below.
+type quotaFile struct {
+	absfs.File
+	q      *quotaFS
+	key    string
+	append bool
+}
+
+// grow charges for writing n bytes at off, or at the current offset if off
+// is negative, and returns the number of bytes charged.
+func (f *quotaFile) grow(off int64, n int) (int64, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if off < 0 {
+		off = size
+		if !f.append {
+			if off, err = f.File.Seek(0, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+	}
+	growth := max(off+int64(n)-size, 0)
+	return growth, f.q.charge("write", f.key, growth)
+}
+
+// wrote credits back the bytes charged for a write of n bytes of which only
+// written were written.
+func (f *quotaFile) wrote(charged int64, n, written int, err error) (int, error) {
+	if unused := min(charged, int64(n-written)); unused > 0 {
+		f.q.charge("write", f.key, -unused)
+	}
+	return written, err
+}
+
+func (f *quotaFile) Write(p []byte) (int, error) {
+	charged, err := f.grow(-1, len(p))
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.File.Write(p)
+	return f.wrote(charged, len(p), n, err)
+}
+
+func (f *quotaFile) WriteAt(b []byte, off int64) (int, error) {
+	charged, err := f.grow(off, len(b))
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.File.WriteAt(b, off)
+	return f.wrote(charged, len(b), n, err)
+}
+
+func (f *quotaFile) WriteString(s string) (int, error) {
+	charged, err := f.grow(-1, len(s))
+	if err != nil {
+		return 0, err
+	}
+	n, err := f.File.WriteString(s)
+	return f.wrote(charged, len(s), n, err)
+}
+
+func (f *quotaFile) Truncate(size int64) error {
+	info, err := f.File.Stat()
+	if err != nil {
+		return err
+	}
+	if err := f.q.charge("truncate", f.key, size-info.Size()); err != nil {
+		return err
+	}
+	err = f.File.Truncate(size)
+	if info, serr := f.File.Stat(); serr == nil {
+		f.q.charge("truncate", f.key, info.Size()-size)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewDirQuotaFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/tenant", "/other"} {
+		if err := mfs.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Existing files count towards the quota.
+	writeFile(t, mfs, "/tenant/old", strings.Repeat("o", 4))
+	fs, err := ptfs.NewDirQuotaFS(mfs, map[string]int64{"/tenant": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, fs, "/tenant/a", "12345")
+	f, err := fs.OpenFile("/tenant/a", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("67")); !errors.Is(err, syscall.EDQUOT) {
+		t.Errorf("Write over quota = %v, want EDQUOT", err)
+	}
+	if _, err := f.Write([]byte("6")); err != nil {
+		t.Errorf("Write within quota = %v", err)
+	}
+	f.Close()
+	if err := fs.Truncate("/tenant/old", 5); !errors.Is(err, syscall.EDQUOT) {
+		t.Errorf("Truncate over quota = %v, want EDQUOT", err)
+	}
+	if got := readAll(t, fs, "/tenant/a"); got != "123456" {
+		t.Errorf("content = %q, want %q", got, "123456")
+	}
+
+	// Other directories are unaffected.
+	writeFile(t, fs, "/other/big", strings.Repeat("x", 100))
+
+	// Moving files into the directory is limited too.
+	if err := fs.Rename("/other/big", "/tenant/big"); !errors.Is(err, syscall.EDQUOT) {
+		t.Errorf("Rename into quota = %v, want EDQUOT", err)
+	}
+
+	// Removing and shrinking files credits their space back.
+	if err := fs.Remove("/tenant/old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Truncate("/tenant/a", 2); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/tenant/b", strings.Repeat("b", 8))
+	f, err = fs.Create("/tenant/d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("d"); !errors.Is(err, syscall.EDQUOT) {
+		t.Errorf("WriteString over quota = %v, want EDQUOT", err)
+	}
+}
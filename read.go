@@ -0,0 +1,145 @@
+package ptfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/absfs/absfs"
+)
+
+// ErrFileTooLarge is returned, wrapped in a *os.PathError, when a file is too
+// large to be read into memory.
+var ErrFileTooLarge = errors.New("file too large")
+
+const maxSeekableSize = 16 << 20
+
+// ReadFileInto reads the named file into buf and returns the number of bytes
+// read. If the file holds more than len(buf) bytes, buf is filled and
+// io.ErrShortBuffer is returned. Reusing buf across calls avoids allocating a
+// new slice for every file read.
+func (f *FileSystem) ReadFileInto(name string, buf []byte) (n int, err error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	n, err = io.ReadFull(file, buf)
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, nil
+	case nil:
+	default:
+		return n, err
+	}
+	var extra [1]byte
+	for {
+		m, err := file.Read(extra[:])
+		if m > 0 {
+			return n, io.ErrShortBuffer
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// SeekableReader reads the named file into memory and returns a reader over
+// its content, whose Seek and ReadAt work even when files of the underlying
+// filesystem only support streaming reads. Files larger than 16MiB are not
+// read and return an error wrapping ErrFileTooLarge.
+func (f *FileSystem) SeekableReader(name string) (*bytes.Reader, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxSeekableSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSeekableSize {
+		return nil, &os.PathError{Op: "read", Path: name, Err: ErrFileTooLarge}
+	}
+	return bytes.NewReader(data), nil
+}
+
+// ReadHeader reads exactly the first n bytes of the named file. If the file
+// is shorter than n bytes, it returns io.ErrUnexpectedEOF, even if the file
+// is empty. A negative n fails with a *os.PathError wrapping syscall.EINVAL.
+func (f *FileSystem) ReadHeader(name string, n int) ([]byte, error) {
+	if n < 0 {
+		return nil, &os.PathError{Op: "read", Path: name, Err: syscall.EINVAL}
+	}
+	file, err := f.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// OpenWithFallback opens primary for reading, or, if primary does not exist,
+// opens fallback instead, as when loading a user's configuration file with a
+// bundled default. It returns the open file and the path that was opened.
+// Errors opening primary other than os.ErrNotExist are returned without
+// trying fallback.
+func (f *FileSystem) OpenWithFallback(primary, fallback string) (absfs.File, string, error) {
+	file, err := f.Open(primary)
+	if err == nil {
+		return file, primary, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, "", err
+	}
+	file, err = f.Open(fallback)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, fallback, nil
+}
+
+// OpenAt opens the named file for reading, positioned offset bytes from its
+// start, so that the first Read returns the content from offset on, as when
+// serving an HTTP range request or resuming a download. If files of the
+// underlying filesystem do not support Seek, the first offset bytes are read
+// and discarded instead. An offset that is negative or past the end of the
+// file returns a *os.PathError wrapping syscall.EINVAL.
+func (f *FileSystem) OpenAt(name string, offset int64) (absfs.File, error) {
+	file, err := f.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if offset < 0 || offset > info.Size() {
+		file.Close()
+		return nil, &os.PathError{Op: "openat", Path: name, Err: syscall.EINVAL}
+	}
+	if file.(*File).SupportsSeek() {
+		_, err = file.Seek(offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, file, offset)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
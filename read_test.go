@@ -0,0 +1,190 @@
+package ptfs_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestReadFileInto(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/small", "hello")
+	writeFile(t, fs, "/exact", "12345678")
+	writeFile(t, fs, "/large", "hello, world")
+
+	buf := make([]byte, 8)
+	n, err := fs.ReadFileInto("/small", buf)
+	if err != nil || string(buf[:n]) != "hello" {
+		t.Fatalf("ReadFileInto(/small) = %q, %v, want %q, nil", buf[:n], err, "hello")
+	}
+	n, err = fs.ReadFileInto("/exact", buf)
+	if err != nil || string(buf[:n]) != "12345678" {
+		t.Fatalf("ReadFileInto(/exact) = %q, %v, want %q, nil", buf[:n], err, "12345678")
+	}
+	n, err = fs.ReadFileInto("/large", buf)
+	if err != io.ErrShortBuffer || string(buf[:n]) != "hello, w" {
+		t.Fatalf("ReadFileInto(/large) = %q, %v, want %q, %v", buf[:n], err, "hello, w", io.ErrShortBuffer)
+	}
+	if _, err := fs.ReadFileInto("/missing", buf); err == nil {
+		t.Fatal("ReadFileInto of a missing file succeeded")
+	}
+}
+
+func TestSeekableReader(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/data", "hello, world")
+
+	r, err := fs.SeekableReader("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Seek(7, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := io.ReadAll(r)
+	if err != nil || string(rest) != "world" {
+		t.Fatalf("read after Seek = %q, %v, want %q", rest, err, "world")
+	}
+	buf := make([]byte, 5)
+	if _, err := r.ReadAt(buf, 0); err != nil || string(buf) != "hello" {
+		t.Fatalf("ReadAt = %q, %v, want %q", buf, err, "hello")
+	}
+
+	f, err := fs.Create("/large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(17 << 20); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := fs.SeekableReader("/large"); !errors.Is(err, ptfs.ErrFileTooLarge) {
+		t.Fatalf("SeekableReader of a large file = %v, want %v", err, ptfs.ErrFileTooLarge)
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/png", "\x89PNG\r\n\x1a\nrest")
+	writeFile(t, fs, "/short", "\x89PN")
+	writeFile(t, fs, "/empty", "")
+
+	header, err := fs.ReadHeader("/png", 8)
+	if err != nil || string(header) != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("ReadHeader(/png) = %q, %v", header, err)
+	}
+	for _, name := range []string{"/short", "/empty"} {
+		if _, err := fs.ReadHeader(name, 8); err != io.ErrUnexpectedEOF {
+			t.Errorf("ReadHeader(%s) = %v, want %v", name, err, io.ErrUnexpectedEOF)
+		}
+	}
+	if _, err := fs.ReadHeader("/png", -1); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("ReadHeader(/png, -1) = %v, want EINVAL", err)
+	}
+}
+
+func TestOpenWithFallback(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/default.conf", "default")
+
+	open := func(primary string) (string, string) {
+		t.Helper()
+		f, name, err := fs.OpenWithFallback(primary, "/default.conf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data), name
+	}
+
+	if content, name := open("/user.conf"); content != "default" || name != "/default.conf" {
+		t.Errorf("missing primary: opened %s with %q", name, content)
+	}
+	writeFile(t, fs, "/user.conf", "user")
+	if content, name := open("/user.conf"); content != "user" || name != "/user.conf" {
+		t.Errorf("existing primary: opened %s with %q", name, content)
+	}
+
+	if _, _, err := fs.OpenWithFallback("/user2.conf", "/missing.conf"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing fallback error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestOpenAt(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		fs   absfs.FileSystem
+	}{
+		{"memfs", mfs},
+		{"noseek", noSeekFS{mfs}},
+	} {
+		fs, err := ptfs.NewFS(test.fs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "/" + test.name
+		writeFile(t, fs, name, "0123456789")
+
+		for _, offset := range []int64{0, 4, 10} {
+			f, err := fs.OpenAt(name, offset)
+			if err != nil {
+				t.Fatalf("%s: OpenAt(%d): %v", test.name, offset, err)
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "0123456789"[offset:]; string(data) != want {
+				t.Errorf("%s: OpenAt(%d) read %q, want %q", test.name, offset, data, want)
+			}
+		}
+		for _, offset := range []int64{-1, 11} {
+			if _, err := fs.OpenAt(name, offset); !errors.Is(err, syscall.EINVAL) {
+				t.Errorf("%s: OpenAt(%d) error = %v, want EINVAL", test.name, offset, err)
+			}
+		}
+	}
+}
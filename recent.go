@@ -0,0 +1,70 @@
+package ptfs
+
+import (
+	"container/heap"
+	"os"
+	"sort"
+)
+
+// RecentFiles returns the limit most recently modified regular files in the
+// tree rooted at root, newest first. Files with equal modification times are
+// ordered by path. Only limit files are held in memory at a time, however
+// large the tree.
+func (f *FileSystem) RecentFiles(root string, limit int) ([]os.FileInfo, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	h := make(recentHeap, 0, limit+1)
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		heap.Push(&h, recentFile{name, info})
+		if len(h) > limit {
+			heap.Pop(&h)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(h, func(i, j int) bool { return h.Less(j, i) })
+	infos := make([]os.FileInfo, len(h))
+	for i, r := range h {
+		infos[i] = r.info
+	}
+	return infos, nil
+}
+
+type recentFile struct {
+	name string
+	info os.FileInfo
+}
+
+// recentHeap is a min heap of files, with the oldest file, and of files with
+// equal modification times the one with the greatest path, at the top.
+type recentHeap []recentFile
+
+func (h recentHeap) Len() int      { return len(h) }
+func (h recentHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h recentHeap) Less(i, j int) bool {
+	ti, tj := h[i].info.ModTime(), h[j].info.ModTime()
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return h[i].name > h[j].name
+}
+
+func (h *recentHeap) Push(x any) { *h = append(*h, x.(recentFile)) }
+
+func (h *recentHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,58 @@
+package ptfs_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestRecentFiles(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/root/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, hours := range map[string]int{
+		"/root/a":     1,
+		"/root/b":     5,
+		"/root/sub/c": 3,
+		"/root/sub/d": 5,
+		"/root/e":     2,
+	} {
+		writeFile(t, fs, name, name)
+		mtime := base.Add(time.Duration(hours) * time.Hour)
+		if err := fs.Chtimes(name, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The directory is newer than every file but is not listed.
+	if err := fs.Chtimes("/root/sub", base.Add(time.Hour*10), base.Add(time.Hour*10)); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := fs.RecentFiles("/root", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	if want := []string{"b", "d", "c"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("RecentFiles = %v, want %v", names, want)
+	}
+
+	if infos, err := fs.RecentFiles("/root", 10); err != nil || len(infos) != 5 {
+		t.Fatalf("RecentFiles with a large limit returned %d files, %v, want 5", len(infos), err)
+	}
+}
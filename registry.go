@@ -0,0 +1,37 @@
+package ptfs
+
+import (
+	"sync"
+
+	"github.com/absfs/absfs"
+)
+
+var registry = struct {
+	sync.RWMutex
+	fs map[string]absfs.FileSystem
+}{fs: make(map[string]absfs.FileSystem)}
+
+// Register makes fs available by name to Lookup anywhere in the program.
+// Registering a name that is already registered replaces the previous
+// filesystem.
+func Register(name string, fs absfs.FileSystem) {
+	registry.Lock()
+	registry.fs[name] = fs
+	registry.Unlock()
+}
+
+// Lookup returns the filesystem registered with name, and whether one was
+// registered.
+func Lookup(name string) (absfs.FileSystem, bool) {
+	registry.RLock()
+	fs, ok := registry.fs[name]
+	registry.RUnlock()
+	return fs, ok
+}
+
+// Unregister removes the filesystem registered with name, if any.
+func Unregister(name string) {
+	registry.Lock()
+	delete(registry.fs, name)
+	registry.Unlock()
+}
@@ -0,0 +1,42 @@
+package ptfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestRegistry(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := ptfs.Lookup("assets"); ok {
+		t.Fatal("Lookup found an unregistered filesystem")
+	}
+	ptfs.Register("assets", fs)
+	got, ok := ptfs.Lookup("assets")
+	if !ok || got != fs {
+		t.Fatalf("Lookup = %v, %t, want the registered filesystem", got, ok)
+	}
+
+	other, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptfs.Register("assets", other)
+	if got, _ := ptfs.Lookup("assets"); got != other {
+		t.Error("Register did not replace the existing filesystem")
+	}
+
+	ptfs.Unregister("assets")
+	if _, ok := ptfs.Lookup("assets"); ok {
+		t.Error("Lookup found an unregistered filesystem")
+	}
+}
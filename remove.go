@@ -0,0 +1,55 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// WithIdempotentRemove makes Remove and RemoveAll succeed when the file to be
+// removed does not exist. Only errors satisfying errors.Is(err,
+// os.ErrNotExist) are ignored, all other errors are returned.
+func WithIdempotentRemove() Option {
+	return func(f *FileSystem) error {
+		f.idempotentRemove = true
+		return nil
+	}
+}
+
+// removed filters the error returned by a remove operation.
+func (f *FileSystem) removed(err error) error {
+	if f.idempotentRemove && errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// RemoveOlderThan walks the tree rooted at root and removes the regular
+// files in it last modified more than age ago, as a cache janitor would,
+// returning the paths removed. Directories, symbolic links and other special
+// files are left alone. Failures to remove a file do not stop the walk, and
+// are returned joined with errors.Join once it completes, along with any
+// error ending the walk itself.
+func (f *FileSystem) RemoveOlderThan(root string, age time.Duration) ([]string, error) {
+	cutoff := time.Now().Add(-age)
+	var removed []string
+	var errs []error
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := f.Remove(name); err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		removed = append(removed, name)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestIdempotentRemove(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Remove error = %v, want ErrNotExist", err)
+	}
+
+	fs, err = ptfs.NewFS(mfs, ptfs.WithIdempotentRemove())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("/missing"); err != nil {
+		t.Errorf("Remove error = %v, want nil", err)
+	}
+	if err := fs.RemoveAll("/missing"); err != nil {
+		t.Errorf("RemoveAll error = %v, want nil", err)
+	}
+	if err := fs.Remove("/missing/child"); err != nil {
+		t.Errorf("Remove error = %v, want nil", err)
+	}
+}
+
+func TestRemoveOlderThan(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/cache/old", 0755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for name, modTime := range map[string]time.Time{
+		"/cache/stale":     now.Add(-2 * time.Hour),
+		"/cache/fresh":     now.Add(-time.Minute),
+		"/cache/old/stale": now.Add(-48 * time.Hour),
+		"/cache/old/fresh": now,
+	} {
+		writeFile(t, fs, name, name)
+		if err := fs.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirTime := now.Add(-72 * time.Hour)
+	if err := fs.Chtimes("/cache/old", dirTime, dirTime); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := fs.RemoveOlderThan("/cache", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(removed)
+	if want := []string{"/cache/old/stale", "/cache/stale"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+	for _, name := range []string{"/cache/fresh", "/cache/old", "/cache/old/fresh"} {
+		if _, err := fs.Stat(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range removed {
+		if _, err := fs.Stat(name); err == nil {
+			t.Errorf("%s still exists", name)
+		}
+	}
+}
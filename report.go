@@ -0,0 +1,92 @@
+package ptfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// A Report describes the optional capabilities a filesystem was found to
+// support by ConformanceReport.
+type Report struct {
+	Symlinks bool // Symlink and Readlink
+	Chown    bool
+	Chtimes  bool // modification times are set and reported
+	StatFS   bool
+	ReadAt   bool
+	WriteAt  bool
+	Seek     bool
+
+	// Errors holds the error returned by each failed probe, keyed by the
+	// name of the Report field it applies to, and "setup" for errors
+	// preparing the probes.
+	Errors map[string]error
+}
+
+// ConformanceReport probes the filesystem for support of optional
+// capabilities by performing harmless operations on files in a temporary
+// directory below TempDir. Everything created by the probes is removed
+// before ConformanceReport returns, including TempDir if it did not exist.
+func (f *FileSystem) ConformanceReport() Report {
+	r := Report{Errors: make(map[string]error)}
+	fail := func(name string, err error) bool {
+		if err != nil {
+			r.Errors[name] = err
+		}
+		return err == nil
+	}
+
+	tmp := f.TempDir()
+	if _, err := f.Stat(tmp); errors.Is(err, os.ErrNotExist) {
+		if !fail("setup", f.MkdirAll(tmp, 0755)) {
+			return r
+		}
+		defer f.RemoveAll(tmp)
+	}
+	dir, err := f.mkdirTemp(tmp, "ptfs-probe")
+	if !fail("setup", err) {
+		return r
+	}
+	defer f.RemoveAll(dir)
+	name := path.Join(dir, "probe")
+
+	file, err := f.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if !fail("setup", err) {
+		return r
+	}
+	if _, err := file.Write([]byte("probe")); !fail("setup", err) {
+		file.Close()
+		return r
+	}
+	pf := file.(*File)
+	r.ReadAt = pf.SupportsReadAt()
+	r.WriteAt = pf.SupportsWriteAt()
+	_, err = file.Seek(0, io.SeekStart)
+	r.Seek = fail("Seek", err)
+	fail("setup", file.Close())
+
+	mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if fail("Chtimes", f.Chtimes(name, mtime, mtime)) {
+		info, err := f.Stat(name)
+		if fail("Chtimes", err) {
+			r.Chtimes = info.ModTime().Equal(mtime)
+		}
+	}
+	r.Chown = fail("Chown", f.Chown(name, os.Getuid(), os.Getgid()))
+
+	_, err = f.StatFS()
+	r.StatFS = fail("StatFS", err)
+
+	if s, ok := f.fs.(interface {
+		Symlink(oldname, newname string) error
+		Readlink(name string) (string, error)
+	}); !ok {
+		fail("Symlinks", ErrNotSupported)
+	} else if link := name + ".link"; fail("Symlinks", s.Symlink(name, link)) {
+		target, err := s.Readlink(link)
+		r.Symlinks = fail("Symlinks", err) && target == name
+	}
+	return r
+}
@@ -0,0 +1,48 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestConformanceReport(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := fs.ConformanceReport()
+	if err := r.Errors["setup"]; err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]bool{
+		"Symlinks": {r.Symlinks, true},
+		"Chown":    {r.Chown, true},
+		"Chtimes":  {r.Chtimes, true},
+		"StatFS":   {r.StatFS, false},
+		"ReadAt":   {r.ReadAt, true},
+		"WriteAt":  {r.WriteAt, true},
+		"Seek":     {r.Seek, true},
+	}
+	for name, v := range want {
+		if v[0] != v[1] {
+			t.Errorf("%s = %t, want %t (error %v)", name, v[0], v[1], r.Errors[name])
+		}
+	}
+	if !errors.Is(r.Errors["StatFS"], ptfs.ErrNotSupported) {
+		t.Errorf("StatFS error = %v, want ErrNotSupported", r.Errors["StatFS"])
+	}
+
+	if _, err := fs.Stat(fs.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		names, _ := readDirNames(fs, fs.TempDir())
+		t.Errorf("probes left artifacts behind: %q", names)
+	}
+}
@@ -0,0 +1,37 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// WithEventualConsistency retries Open, Stat and Lstat up to retries times,
+// waiting delay between attempts, while they fail with an error satisfying
+// errors.Is(err, os.ErrNotExist). This hides propagation delays of eventually
+// consistent storage, where a file that was just written may briefly appear
+// not to exist. Operations that modify the filesystem are never retried.
+//
+// Looking up a file that genuinely does not exist takes retries*delay longer
+// to fail.
+func WithEventualConsistency(retries int, delay time.Duration) Option {
+	return func(f *FileSystem) error {
+		if retries < 0 || delay < 0 {
+			return errors.New("ptfs: negative retries or delay")
+		}
+		f.retries = retries
+		f.retryDelay = delay
+		return nil
+	}
+}
+
+// retry calls fn, calling it again according to WithEventualConsistency while
+// it reports that a file does not exist.
+func (f *FileSystem) retry(fn func() error) error {
+	err := fn()
+	for i := 0; i < f.retries && errors.Is(err, os.ErrNotExist); i++ {
+		time.Sleep(f.retryDelay)
+		err = fn()
+	}
+	return err
+}
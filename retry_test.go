@@ -0,0 +1,55 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// laggyFS reports files as missing the first misses times they are looked up.
+type laggyFS struct {
+	absfs.FileSystem
+	misses int
+	calls  int
+}
+
+func (fs *laggyFS) Stat(name string) (os.FileInfo, error) {
+	fs.calls++
+	if fs.calls <= fs.misses {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return fs.FileSystem.Stat(name)
+}
+
+func TestEventualConsistency(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/new", "data")
+
+	lfs := &laggyFS{FileSystem: mfs, misses: 2}
+	fs, err := ptfs.NewFS(lfs, ptfs.WithEventualConsistency(3, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("/new"); err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if lfs.calls != 3 {
+		t.Errorf("backend Stat calls = %d, want 3", lfs.calls)
+	}
+
+	lfs.calls = 0
+	if _, err := fs.Stat("/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat error = %v, want ErrNotExist", err)
+	}
+	if lfs.calls != 4 {
+		t.Errorf("backend Stat calls = %d, want 4", lfs.calls)
+	}
+}
@@ -0,0 +1,63 @@
+package ptfs
+
+import (
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// NewRewriteFS returns a FileSystem that passes every path through rewrite
+// before delegating to fs. rewrite is called with the name of the operation,
+// such as "OpenFile" or "Rename", and the path given by the caller, and
+// returns the path to use in fs, or an error that the operation fails with.
+// Both paths of Rename and Symlink are rewritten. Getwd reports the working
+// directory of fs unchanged; use WithRewrite to also provide an inverse.
+func NewRewriteFS(fs absfs.FileSystem, rewrite func(op, path string) (string, error), opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithRewrite(rewrite, nil)}, opts...)...)
+}
+
+// WithRewrite adds a path rewrite as described by NewRewriteFS. If inverse is
+// not nil, it maps paths of the underlying filesystem back to the paths seen
+// by callers, and is applied to the directory reported by Getwd. Rewrites
+// given by several options are applied in order.
+func WithRewrite(rewrite func(op, path string) (string, error), inverse func(path string) string) Option {
+	return func(f *FileSystem) error {
+		f.rewrites = append(f.rewrites, pathRewrite{rewrite, inverse})
+		return nil
+	}
+}
+
+type pathRewrite struct {
+	rewrite func(op, path string) (string, error)
+	inverse func(path string) string
+}
+
+// mapPath returns the name of a file in the underlying filesystem.
+func (f *FileSystem) mapPath(op, name string) (string, error) {
+	for _, r := range f.rewrites {
+		var err error
+		if name, err = r.rewrite(op, name); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// virtualPath is the inverse of mapPath.
+func (f *FileSystem) virtualPath(name string) string {
+	for i := len(f.rewrites) - 1; i >= 0; i-- {
+		if inverse := f.rewrites[i].inverse; inverse != nil {
+			name = inverse(name)
+		}
+	}
+	return name
+}
+
+// NewLowercaseFS returns a FileSystem that lowercases every path before
+// passing it to fs, so "/Foo/Bar.TXT" and "/foo/bar.txt" name the same file,
+// stored as "/foo/bar.txt". The mapping is lossy: the original case of names
+// is not preserved, and Readdir and Getwd report the lowercased names.
+func NewLowercaseFS(fs absfs.FileSystem, opts ...Option) (*FileSystem, error) {
+	lower := func(op, name string) (string, error) { return strings.ToLower(name), nil }
+	return NewFS(fs, append([]Option{WithRewrite(lower, nil)}, opts...)...)
+}
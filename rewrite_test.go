@@ -0,0 +1,89 @@
+package ptfs_test
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewRewriteFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfs.MkdirAll("/tenants/acme", 0755); err != nil {
+		t.Fatal(err)
+	}
+	errForbidden := errors.New("forbidden")
+	tenant := func(op, name string) (string, error) {
+		if strings.HasPrefix(name, "/secret") {
+			return "", errForbidden
+		}
+		return path.Join("/tenants/acme", name), nil
+	}
+	fs, err := ptfs.NewRewriteFS(mfs, tenant)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, fs, "/a", "data")
+	if err := fs.Rename("/a", "/b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, mfs, "/tenants/acme/b"); got != "data" {
+		t.Fatalf("underlying file holds %q, want %q", got, "data")
+	}
+	if _, err := fs.Stat("/secret"); !errors.Is(err, errForbidden) {
+		t.Fatalf("Stat(/secret) = %v, want %v", err, errForbidden)
+	}
+
+	if err := fs.Chdir("/"); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := fs.Getwd(); dir != "/tenants/acme" {
+		t.Fatalf("Getwd without inverse = %q, want %q", dir, "/tenants/acme")
+	}
+
+	untenant := func(name string) string {
+		return path.Join("/", strings.TrimPrefix(name, "/tenants/acme"))
+	}
+	fs, err = ptfs.NewFS(mfs, ptfs.WithRewrite(tenant, untenant))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := fs.Getwd(); dir != "/" {
+		t.Fatalf("Getwd with inverse = %q, want %q", dir, "/")
+	}
+}
+
+func TestNewLowercaseFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewLowercaseFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/Dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/Dir/FOO", "data")
+	if got := readAll(t, fs, "/dir/foo"); got != "data" {
+		t.Fatalf("read /dir/foo = %q, want %q", got, "data")
+	}
+	if got := readAll(t, mfs, "/dir/foo"); got != "data" {
+		t.Fatalf("backend /dir/foo = %q, want %q", got, "data")
+	}
+	names, err := readDirNames(fs, "/DIR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "foo" {
+		t.Fatalf("Readdir = %v, want [foo]", names)
+	}
+}
@@ -0,0 +1,186 @@
+package ptfs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewRouterFS returns a FileSystem serving root, with the filesystems in
+// mounts mounted at the absolute paths they are keyed by. A path at or below
+// a mount point is served by the mounted filesystem, with the mount point
+// removed, so with "/data" mounted, "/data/file" is "/file" in the mounted
+// filesystem. When mount points are nested the longest one applies. Rename
+// between different filesystems fails with a *os.LinkError wrapping
+// syscall.EXDEV.
+func NewRouterFS(root absfs.FileSystem, mounts map[string]absfs.FileSystem, opts ...Option) (*FileSystem, error) {
+	r := &routerFS{root: root, cwd: "/"}
+	for prefix, fs := range mounts {
+		if !path.IsAbs(prefix) {
+			return nil, &os.PathError{Op: "mount", Path: prefix, Err: syscall.EINVAL}
+		}
+		r.mounts = append(r.mounts, mount{path.Clean(prefix), fs})
+	}
+	sort.Slice(r.mounts, func(i, j int) bool {
+		return len(r.mounts[i].prefix) > len(r.mounts[j].prefix)
+	})
+	return NewFS(r, opts...)
+}
+
+// A MountInfo describes a filesystem mounted in a router filesystem.
+type MountInfo struct {
+	Prefix  string
+	Backend string // the type of the filesystem below any pass through layers
+}
+
+// Mounts returns the filesystems mounted in a FileSystem returned by
+// NewRouterFS, sorted by prefix, or an empty slice for any other FileSystem.
+func (f *FileSystem) Mounts() []MountInfo {
+	r, ok := f.fs.(*routerFS)
+	if !ok {
+		return []MountInfo{}
+	}
+	mounts := make([]MountInfo, len(r.mounts))
+	for i, m := range r.mounts {
+		mounts[i] = MountInfo{m.prefix, fmt.Sprintf("%T", DeepUnwrapFS(m.fs))}
+	}
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Prefix < mounts[j].Prefix })
+	return mounts
+}
+
+// routerFS adapts a root filesystem with mounted filesystems to
+// absfs.FileSystem.
+type routerFS struct {
+	root   absfs.FileSystem
+	mounts []mount // longest prefix first
+
+	mu  sync.Mutex
+	cwd string
+}
+
+type mount struct {
+	prefix string
+	fs     absfs.FileSystem
+}
+
+// route returns the filesystem serving name, and the name in it.
+func (r *routerFS) route(name string) (absfs.FileSystem, string) {
+	if !path.IsAbs(name) {
+		r.mu.Lock()
+		name = path.Join(r.cwd, name)
+		r.mu.Unlock()
+	}
+	name = path.Clean(name)
+	for _, m := range r.mounts {
+		if rest, ok := cutPath(name, m.prefix); ok {
+			if rest == "" {
+				rest = "/"
+			}
+			return m.fs, rest
+		}
+	}
+	return r.root, name
+}
+
+func (r *routerFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	fs, name := r.route(name)
+	return fs.OpenFile(name, flag, perm)
+}
+
+func (r *routerFS) Open(name string) (absfs.File, error) {
+	fs, name := r.route(name)
+	return fs.Open(name)
+}
+
+func (r *routerFS) Create(name string) (absfs.File, error) {
+	fs, name := r.route(name)
+	return fs.Create(name)
+}
+
+func (r *routerFS) Mkdir(name string, perm os.FileMode) error {
+	fs, name := r.route(name)
+	return fs.Mkdir(name, perm)
+}
+
+func (r *routerFS) MkdirAll(name string, perm os.FileMode) error {
+	fs, name := r.route(name)
+	return fs.MkdirAll(name, perm)
+}
+
+func (r *routerFS) Remove(name string) error {
+	fs, name := r.route(name)
+	return fs.Remove(name)
+}
+
+func (r *routerFS) RemoveAll(name string) error {
+	fs, name := r.route(name)
+	return fs.RemoveAll(name)
+}
+
+func (r *routerFS) Rename(oldname, newname string) error {
+	oldfs, oldpath := r.route(oldname)
+	newfs, newpath := r.route(newname)
+	if oldfs != newfs {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EXDEV}
+	}
+	return oldfs.Rename(oldpath, newpath)
+}
+
+func (r *routerFS) Stat(name string) (os.FileInfo, error) {
+	fs, name := r.route(name)
+	return fs.Stat(name)
+}
+
+func (r *routerFS) Chmod(name string, mode os.FileMode) error {
+	fs, name := r.route(name)
+	return fs.Chmod(name, mode)
+}
+
+func (r *routerFS) Chtimes(name string, atime, mtime time.Time) error {
+	fs, name := r.route(name)
+	return fs.Chtimes(name, atime, mtime)
+}
+
+func (r *routerFS) Chown(name string, uid, gid int) error {
+	fs, name := r.route(name)
+	return fs.Chown(name, uid, gid)
+}
+
+func (r *routerFS) Truncate(name string, size int64) error {
+	fs, name := r.route(name)
+	return fs.Truncate(name, size)
+}
+
+func (r *routerFS) Chdir(dir string) error {
+	info, err := r.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "chdir", Path: dir, Err: syscall.ENOTDIR}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if path.IsAbs(dir) {
+		r.cwd = path.Clean(dir)
+	} else {
+		r.cwd = path.Join(r.cwd, dir)
+	}
+	return nil
+}
+
+func (r *routerFS) Getwd() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cwd, nil
+}
+
+func (r *routerFS) Separator() uint8     { return '/' }
+func (r *routerFS) ListSeparator() uint8 { return ':' }
+func (r *routerFS) TempDir() string      { return r.root.TempDir() }
@@ -0,0 +1,74 @@
+package ptfs_test
+
+import (
+	"errors"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewRouterFS(t *testing.T) {
+	root, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := ptfs.NewFS(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewRouterFS(root, map[string]absfs.FileSystem{
+		"/var/cache": wrapped,
+		"/data":      data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, fs, "/data/file", "data")
+	writeFile(t, fs, "/var/cache/file", "cache")
+	if err := fs.MkdirAll("/var", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/var/file", "root")
+	for mfs, want := range map[*memfs.FileSystem]string{data: "data", cache: "cache"} {
+		if got := readAll(t, mfs, "/file"); got != want {
+			t.Errorf("mounted /file = %q, want %q", got, want)
+		}
+	}
+	if got := readAll(t, root, "/var/file"); got != "root" {
+		t.Errorf("root /var/file = %q, want %q", got, "root")
+	}
+
+	if err := fs.Rename("/data/file", "/var/moved"); !errors.Is(err, syscall.EXDEV) {
+		t.Fatalf("Rename across mounts = %v, want %v", err, syscall.EXDEV)
+	}
+	if err := fs.Chdir("/data"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "file"); got != "data" {
+		t.Fatalf("relative read after Chdir = %q, want %q", got, "data")
+	}
+
+	want := []ptfs.MountInfo{
+		{Prefix: "/data", Backend: "*memfs.FileSystem"},
+		{Prefix: "/var/cache", Backend: "*memfs.FileSystem"},
+	}
+	if got := fs.Mounts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Mounts() = %v, want %v", got, want)
+	}
+	if got := wrapped.Mounts(); got == nil || len(got) != 0 {
+		t.Fatalf("Mounts() of a non-router = %#v, want an empty slice", got)
+	}
+}
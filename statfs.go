@@ -0,0 +1,23 @@
+package ptfs
+
+// FSStats describes the space available on a filesystem.
+type FSStats struct {
+	TotalBytes uint64
+	FreeBytes  uint64
+	UsedBytes  uint64
+}
+
+// A StatFSer is a filesystem that can report its space usage.
+type StatFSer interface {
+	StatFS() (FSStats, error)
+}
+
+// StatFS returns the space usage of the underlying filesystem. Pass through
+// filesystems are unwrapped to find a filesystem implementing StatFSer, if
+// none does StatFS returns ErrNotSupported.
+func (f *FileSystem) StatFS() (FSStats, error) {
+	if f.caps.statfser != nil {
+		return f.caps.statfser.StatFS()
+	}
+	return FSStats{}, ErrNotSupported
+}
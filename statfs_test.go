@@ -0,0 +1,49 @@
+package ptfs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+type statFS struct {
+	absfs.FileSystem
+}
+
+func (statFS) StatFS() (ptfs.FSStats, error) {
+	return ptfs.FSStats{TotalBytes: 100, FreeBytes: 60, UsedBytes: 40}, nil
+}
+
+func TestStatFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner, err := ptfs.NewFS(statFS{mfs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := fs.StatFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ptfs.FSStats{TotalBytes: 100, FreeBytes: 60, UsedBytes: 40}); stats != want {
+		t.Errorf("StatFS = %+v, want %+v", stats, want)
+	}
+
+	fs, err = ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.StatFS(); !errors.Is(err, ptfs.ErrNotSupported) {
+		t.Errorf("StatFS error = %v, want ErrNotSupported", err)
+	}
+}
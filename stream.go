@@ -0,0 +1,45 @@
+package ptfs
+
+import "io"
+
+// StreamTo copies the content of the named file to w, and returns the number
+// of bytes copied. If progress is not nil, it is called after each chunk is
+// written with the number of bytes copied so far and the size of the file
+// when it was opened.
+func (f *FileSystem) StreamTo(name string, w io.Writer, progress func(copied, total int64)) (int64, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	total := info.Size()
+
+	var copied int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, rerr := file.Read(buf)
+		if n > 0 {
+			m, werr := w.Write(buf[:n])
+			copied += int64(m)
+			if werr == nil && m < n {
+				werr = io.ErrShortWrite
+			}
+			if werr != nil {
+				return copied, werr
+			}
+			if progress != nil {
+				progress(copied, total)
+			}
+		}
+		if rerr == io.EOF {
+			return copied, nil
+		}
+		if rerr != nil {
+			return copied, rerr
+		}
+	}
+}
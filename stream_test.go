@@ -0,0 +1,53 @@
+package ptfs_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestStreamTo(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Repeat("0123456789", 10000)
+	writeFile(t, fs, "/upload", content)
+
+	var buf bytes.Buffer
+	var last int64
+	calls := 0
+	n, err := fs.StreamTo("/upload", &buf, func(copied, total int64) {
+		calls++
+		if copied <= last {
+			t.Errorf("progress went from %d to %d", last, copied)
+		}
+		if total != int64(len(content)) {
+			t.Errorf("total = %d, want %d", total, len(content))
+		}
+		last = copied
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) || last != n {
+		t.Errorf("copied %d, last progress %d, want %d", n, last, len(content))
+	}
+	if calls < 2 {
+		t.Errorf("progress called %d times, want several", calls)
+	}
+	if buf.String() != content {
+		t.Error("streamed content differs from the file")
+	}
+
+	if _, err := fs.StreamTo("/upload", &buf, nil); err != nil {
+		t.Fatal(err)
+	}
+}
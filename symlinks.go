@@ -0,0 +1,105 @@
+package ptfs
+
+import (
+	"errors"
+	"os"
+	"path"
+)
+
+// A SymlinkIssueKind classifies a problem found by ValidateSymlinks.
+type SymlinkIssueKind int
+
+const (
+	// SymlinkDangling is a symbolic link whose target does not exist.
+	SymlinkDangling SymlinkIssueKind = iota + 1
+
+	// SymlinkLoop is a symbolic link that does not resolve within the
+	// maximum number of links followed, usually because of a cycle.
+	SymlinkLoop
+
+	// SymlinkEscape is a symbolic link resolving to a path outside the
+	// tree being validated.
+	SymlinkEscape
+)
+
+func (k SymlinkIssueKind) String() string {
+	switch k {
+	case SymlinkDangling:
+		return "dangling"
+	case SymlinkLoop:
+		return "loop"
+	case SymlinkEscape:
+		return "escape"
+	}
+	return "unknown"
+}
+
+// A SymlinkIssue describes a problem with a symbolic link found by
+// ValidateSymlinks.
+type SymlinkIssue struct {
+	Path   string // the symbolic link
+	Kind   SymlinkIssueKind
+	Detail string // the path at which the problem was found
+}
+
+// ValidateSymlinks walks the tree rooted at root without following symbolic
+// links, and resolves every symbolic link it finds, reporting links that are
+// dangling, that do not resolve within maxDepth links, or that resolve to a
+// path outside root at any step. Links are resolved one at a time with
+// Readlink and Lstat, relative targets being relative to the directory
+// containing the link. A maxDepth of 0 or less follows up to 40 links, as
+// Linux does. Problems with links are returned as issues, in walk order, and
+// the error is only non-nil if the tree cannot be walked.
+func (f *SymlinkFileSystem) ValidateSymlinks(root string, maxDepth int) ([]SymlinkIssue, error) {
+	if maxDepth <= 0 {
+		maxDepth = 40
+	}
+	root = path.Clean(root)
+	var issues []SymlinkIssue
+	err := f.fs.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		kind, detail, err := f.resolve(root, name, maxDepth)
+		if err != nil {
+			return err
+		}
+		if kind != 0 {
+			issues = append(issues, SymlinkIssue{Path: name, Kind: kind, Detail: detail})
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// resolve follows the symbolic link name, and returns the kind of problem
+// found and the path it was found at, or 0 if the link resolves within root.
+func (f *SymlinkFileSystem) resolve(root, name string, maxDepth int) (SymlinkIssueKind, string, error) {
+	for depth := 0; depth < maxDepth; depth++ {
+		target, err := f.Readlink(name)
+		if err != nil {
+			return 0, "", err
+		}
+		if !path.IsAbs(target) {
+			target = path.Join(path.Dir(name), target)
+		}
+		if _, ok := cutPath(path.Clean(target), root); !ok {
+			return SymlinkEscape, target, nil
+		}
+		info, err := f.Lstat(target)
+		if errors.Is(err, os.ErrNotExist) {
+			return SymlinkDangling, target, nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return 0, "", nil
+		}
+		name = target
+	}
+	return SymlinkLoop, name, nil
+}
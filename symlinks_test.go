@@ -0,0 +1,52 @@
+package ptfs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestValidateSymlinks(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewSymlinkFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirAll("/root/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/root/file", "data")
+	writeFile(t, fs, "/outside", "data")
+	for link, target := range map[string]string{
+		"/root/dir/good": "../file",
+		"/root/dangling": "/root/missing",
+		"/root/loop":     "loop",
+		"/root/escape":   "../outside",
+	} {
+		if err := fs.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues, err := fs.ValidateSymlinks("/root", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]ptfs.SymlinkIssueKind)
+	for _, issue := range issues {
+		got[issue.Path] = issue.Kind
+	}
+	want := map[string]ptfs.SymlinkIssueKind{
+		"/root/dangling": ptfs.SymlinkDangling,
+		"/root/escape":   ptfs.SymlinkEscape,
+		"/root/loop":     ptfs.SymlinkLoop,
+	}
+	if len(issues) != 3 || !reflect.DeepEqual(got, want) {
+		t.Fatalf("ValidateSymlinks = %v, want %v", issues, want)
+	}
+}
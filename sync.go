@@ -0,0 +1,203 @@
+package ptfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// A SyncOp is the kind of change made by Sync.
+type SyncOp int
+
+const (
+	// SyncMkdir is a directory created in the destination.
+	SyncMkdir SyncOp = iota + 1
+
+	// SyncCopy is a file copied to the destination, where it did not exist.
+	SyncCopy
+
+	// SyncUpdate is a file of the destination replaced because it differed
+	// from the source.
+	SyncUpdate
+
+	// SyncRemove is a file or directory removed from the destination, because
+	// it is not in the source or is of a different type than in the source.
+	SyncRemove
+)
+
+func (op SyncOp) String() string {
+	switch op {
+	case SyncMkdir:
+		return "mkdir"
+	case SyncCopy:
+		return "copy"
+	case SyncUpdate:
+		return "update"
+	case SyncRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// A SyncAction is a change made by Sync to the destination path Path.
+type SyncAction struct {
+	Op   SyncOp
+	Path string
+}
+
+// A SyncCompare reports whether the regular file srcName in src, described by
+// srcInfo, differs from the regular file dstName in dst, described by
+// dstInfo, so that Sync must copy it again.
+type SyncCompare func(src, dst absfs.FileSystem, srcName, dstName string, srcInfo, dstInfo os.FileInfo) (bool, error)
+
+// SyncByModTime is a SyncCompare treating files as different if their sizes
+// or modification times differ. It is the default, and is cheap but relies on
+// Sync copying modification times, so it is fooled by a destination file
+// changed without changing its size or modification time.
+func SyncByModTime(src, dst absfs.FileSystem, srcName, dstName string, srcInfo, dstInfo os.FileInfo) (bool, error) {
+	return srcInfo.Size() != dstInfo.Size() || !srcInfo.ModTime().Equal(dstInfo.ModTime()), nil
+}
+
+// SyncByHash is a SyncCompare treating files as different if their sizes or
+// the SHA-256 hashes of their content differ. It reads both files whenever
+// their sizes are equal.
+func SyncByHash(src, dst absfs.FileSystem, srcName, dstName string, srcInfo, dstInfo os.FileInfo) (bool, error) {
+	if srcInfo.Size() != dstInfo.Size() {
+		return true, nil
+	}
+	srcSum, err := sha256File(src, srcName)
+	if err != nil {
+		return false, err
+	}
+	dstSum, err := sha256File(dst, dstName)
+	return srcSum != dstSum, err
+}
+
+// WithSyncCompare sets how Sync decides whether a file present in both the
+// source and the destination must be copied again. The default is
+// SyncByModTime.
+func WithSyncCompare(cmp SyncCompare) Option {
+	return func(f *FileSystem) error {
+		f.syncCompare = cmp
+		return nil
+	}
+}
+
+// Sync makes the tree rooted at dstRoot in the FileSystem match the tree
+// rooted at srcRoot in src, and returns the changes made, in the order they
+// were made. Missing directories are created, and files that are missing or
+// differ, as decided by the SyncCompare set with WithSyncCompare, are copied
+// along with their permissions and modification times. If del is true,
+// entries of the destination that are not in the source are removed.
+// Symbolic links in the source are skipped. The source is walked in lexical
+// order, so on error the changes returned are those made before it.
+func (f *FileSystem) Sync(src absfs.FileSystem, srcRoot, dstRoot string, del bool) ([]SyncAction, error) {
+	cmp := f.syncCompare
+	if cmp == nil {
+		cmp = SyncByModTime
+	}
+	from, err := NewFS(src)
+	if err != nil {
+		return nil, err
+	}
+	srcRoot, dstRoot = path.Clean(srcRoot), path.Clean(dstRoot)
+
+	var actions []SyncAction
+	seen := make(map[string]bool)
+	err = from.Walk(srcRoot, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(path.Clean(name), srcRoot)
+		dst := path.Join(dstRoot, rel)
+		seen[dst] = true
+		mode := info.Mode()
+		if mode&os.ModeSymlink != 0 {
+			return nil
+		}
+
+		dinfo, err := f.Stat(dst)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err == nil && dinfo.IsDir() != mode.IsDir() {
+			if err := f.RemoveAll(dst); err != nil {
+				return err
+			}
+			actions = append(actions, SyncAction{SyncRemove, dst})
+			dinfo = nil
+		}
+
+		switch {
+		case mode.IsDir():
+			if dinfo == nil {
+				if err := f.MkdirAll(dst, mode.Perm()); err != nil {
+					return err
+				}
+				actions = append(actions, SyncAction{SyncMkdir, dst})
+			}
+		case dinfo == nil:
+			actions = append(actions, SyncAction{SyncCopy, dst})
+			return f.syncFile(src, name, dst, info)
+		default:
+			changed, err := cmp(src, f, name, dst, info, dinfo)
+			if err != nil || !changed {
+				return err
+			}
+			actions = append(actions, SyncAction{SyncUpdate, dst})
+			return f.syncFile(src, name, dst, info)
+		}
+		return nil
+	})
+	if err != nil || !del {
+		return actions, err
+	}
+
+	err = f.Walk(dstRoot, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if seen[path.Clean(name)] {
+			return nil
+		}
+		if err := f.RemoveAll(name); err != nil {
+			return err
+		}
+		actions = append(actions, SyncAction{SyncRemove, name})
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return actions, err
+}
+
+// syncFile copies the regular file name in src, described by info, to dst,
+// with its permissions and modification time.
+func (f *FileSystem) syncFile(src absfs.FileSystem, name, dst string, info os.FileInfo) error {
+	in, err := src.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := f.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.Chmod(dst, info.Mode().Perm())
+	}
+	if err == nil {
+		err = f.Chtimes(dst, info.ModTime(), info.ModTime())
+	}
+	return err
+}
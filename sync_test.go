@@ -0,0 +1,113 @@
+package ptfs_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestSync(t *testing.T) {
+	src, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.MkdirAll("/site/css", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, src, "/site/index.html", "index v1")
+	writeFile(t, src, "/site/css/main.css", "body{}")
+
+	actions, err := fs.Sync(src, "/site", "/www", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ptfs.SyncAction{
+		{ptfs.SyncMkdir, "/www"},
+		{ptfs.SyncMkdir, "/www/css"},
+		{ptfs.SyncCopy, "/www/css/main.css"},
+		{ptfs.SyncCopy, "/www/index.html"},
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Fatalf("first sync = %v, want %v", actions, want)
+	}
+
+	// An unchanged tree needs no actions.
+	if actions, err := fs.Sync(src, "/site", "/www", true); err != nil || len(actions) != 0 {
+		t.Fatalf("second sync = %v, %v, want no actions", actions, err)
+	}
+
+	writeFile(t, src, "/site/index.html", "index v2")
+	src.Chtimes("/site/index.html", time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	writeFile(t, src, "/site/new.txt", "new")
+	if err := src.RemoveAll("/site/css"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/www/stale.txt", "stale")
+
+	actions, err = fs.Sync(src, "/site", "/www", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []ptfs.SyncAction{
+		{ptfs.SyncUpdate, "/www/index.html"},
+		{ptfs.SyncCopy, "/www/new.txt"},
+		{ptfs.SyncRemove, "/www/css"},
+		{ptfs.SyncRemove, "/www/stale.txt"},
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Fatalf("third sync = %v, want %v", actions, want)
+	}
+	if got := readAll(t, fs, "/www/index.html"); got != "index v2" {
+		t.Errorf("index.html = %q, want %q", got, "index v2")
+	}
+	names, err := readDirNames(fs, "/www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"index.html", "new.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("/www = %v, want %v", names, want)
+	}
+}
+
+func TestSyncByHash(t *testing.T) {
+	src, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithSyncCompare(ptfs.SyncByHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, src, "/file", "aaaa")
+	writeFile(t, fs, "/file", "bbbb")
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	src.Chtimes("/file", mtime, mtime)
+	fs.Chtimes("/file", mtime, mtime)
+
+	// Same size and modification time, but different content.
+	actions, err := fs.Sync(src, "/file", "/file", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ptfs.SyncAction{{ptfs.SyncUpdate, "/file"}}; !reflect.DeepEqual(actions, want) {
+		t.Fatalf("sync = %v, want %v", actions, want)
+	}
+	if got := readAll(t, fs, "/file"); got != "aaaa" {
+		t.Errorf("content = %q, want %q", got, "aaaa")
+	}
+}
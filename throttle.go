@@ -0,0 +1,76 @@
+package ptfs
+
+import (
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewThrottledFS returns a FileSystem simulating slow storage, for testing
+// how clients behave against it. Each operation of the FileSystem is delayed
+// by latency, and reads and writes of files opened through it are limited to
+// bytesPerSec. A zero latency or bytesPerSec disables that limit.
+func NewThrottledFS(fs absfs.FileSystem, latency time.Duration, bytesPerSec int64, opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithLatency(latency), WithBandwidth(bytesPerSec)}, opts...)...)
+}
+
+// WithLatency delays every operation of the FileSystem, other than those
+// on open files, by d.
+func WithLatency(d time.Duration) Option {
+	return func(f *FileSystem) error {
+		f.latency = d
+		return nil
+	}
+}
+
+// WithBandwidth limits the throughput of Read, Write, ReadAt, WriteAt and
+// WriteString on files opened through the FileSystem to bytesPerSec, by
+// sleeping after each transfer for as long as the transferred bytes would
+// take at that rate. Each file is limited separately.
+func WithBandwidth(bytesPerSec int64) Option {
+	return func(f *FileSystem) error {
+		f.bandwidth = bytesPerSec
+		return nil
+	}
+}
+
+type throttledFile struct {
+	absfs.File
+	bytesPerSec int64
+}
+
+func (f *throttledFile) wait(n int) {
+	if n > 0 {
+		time.Sleep(time.Duration(int64(n) * int64(time.Second) / f.bytesPerSec))
+	}
+}
+
+func (f *throttledFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.wait(n)
+	return n, err
+}
+
+func (f *throttledFile) ReadAt(b []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(b, off)
+	f.wait(n)
+	return n, err
+}
+
+func (f *throttledFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.wait(n)
+	return n, err
+}
+
+func (f *throttledFile) WriteAt(b []byte, off int64) (int, error) {
+	n, err := f.File.WriteAt(b, off)
+	f.wait(n)
+	return n, err
+}
+
+func (f *throttledFile) WriteString(s string) (int, error) {
+	n, err := f.File.WriteString(s)
+	f.wait(n)
+	return n, err
+}
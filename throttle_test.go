@@ -0,0 +1,41 @@
+package ptfs_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewThrottledFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/data", strings.Repeat("x", 1000))
+
+	const latency = 20 * time.Millisecond
+	fs, err := ptfs.NewThrottledFS(mfs, latency, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	f, err := fs.Open("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(io.Discard, f)
+	f.Close()
+	if err != nil || n != 1000 {
+		t.Fatalf("read %d bytes, %v, want 1000", n, err)
+	}
+	// 1000 bytes at 10000 bytes per second take 100ms, plus the latency
+	// of Open.
+	if elapsed, want := time.Since(start), latency+100*time.Millisecond; elapsed < want {
+		t.Fatalf("read took %v, want at least %v", elapsed, want)
+	}
+
+}
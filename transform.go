@@ -0,0 +1,59 @@
+package ptfs
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// TransformWith pipes the content of the named file through cmd, and
+// replaces the file with the command's output, as a filter such as gzip or
+// sed would be used in a shell. The content is streamed to the command's
+// standard input and its standard output to a temporary file, which is
+// renamed over name, with the permissions of the original, once the command
+// exits successfully. If the command fails to start, exits with a non-zero
+// status, or writing its output fails, the temporary file is removed, name
+// is left unchanged, and the error is returned. TransformWith sets cmd's
+// Stdin and Stdout, which must be nil; its Stderr is left as the caller set
+// it.
+//
+// The command runs with the privileges of the program, and is given the
+// file's content, so cmd must be trusted, and must not be built from
+// untrusted input such as file names without care.
+func (f *FileSystem) TransformWith(name string, cmd *exec.Cmd) error {
+	if cmd.Stdin != nil || cmd.Stdout != nil {
+		return errors.New("ptfs: TransformWith: Stdin or Stdout already set")
+	}
+	in, err := f.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin = in
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	tmpName, err := f.writeTemp(name, out, info.Mode().Perm())
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		f.Remove(tmpName)
+		return err
+	}
+	if err := f.Rename(tmpName, name); err != nil {
+		f.Remove(tmpName)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package ptfs_test
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestTransformWith(t *testing.T) {
+	tr, err := exec.LookPath("tr")
+	if err != nil {
+		t.Skip("tr not found")
+	}
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/dir/file", "hello, world\n")
+	if err := fs.Chmod("/dir/file", 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.TransformWith("/dir/file", exec.Command(tr, "a-z", "A-Z")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/dir/file"); got != "HELLO, WORLD\n" {
+		t.Errorf("content = %q, want %q", got, "HELLO, WORLD\n")
+	}
+	assertMode(t, fs, "/dir/file", 0640)
+
+	// A failing command leaves the file unchanged.
+	if err := fs.TransformWith("/dir/file", exec.Command(tr, "--no-such-option")); err == nil {
+		t.Fatal("TransformWith with a failing command succeeded")
+	}
+	if got := readAll(t, fs, "/dir/file"); got != "HELLO, WORLD\n" {
+		t.Errorf("content after failure = %q", got)
+	}
+	names, err := readDirNames(fs, "/dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"file"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("/dir = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,78 @@
+package ptfs
+
+import (
+	"io"
+	"os"
+
+	"github.com/absfs/absfs"
+)
+
+// truncate truncates the named file in the underlying filesystem to size. If
+// that fails to grow the file, either by not changing its size or by
+// returning an error satisfying isNotSupported, the file is extended by
+// writing zeros, so that growing a file behaves as POSIX truncate does on
+// every filesystem, at the cost of a Stat, and an open and write when
+// extending.
+func (f *FileSystem) truncate(name string, size int64) error {
+	err := f.fs.Truncate(name, size)
+	if err != nil && !isNotSupported(err) {
+		return err
+	}
+	info, serr := f.fs.Stat(name)
+	if serr != nil || info.Size() >= size {
+		if err == nil {
+			err = serr
+		}
+		return err
+	}
+	file, err := f.fs.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	err = zeroExtend(file, info.Size(), size)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Truncate changes the size of the file. Growing the file extends it with
+// zeros even if the underlying file does not, as FileSystem.Truncate does.
+func (f *File) Truncate(size int64) error {
+	err := f.f.Truncate(size)
+	if err != nil && !isNotSupported(err) {
+		return err
+	}
+	info, serr := f.f.Stat()
+	if serr != nil || info.Size() >= size {
+		if err == nil {
+			err = serr
+		}
+		return err
+	}
+	off, err := f.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if err := zeroExtend(f.f, info.Size(), size); err != nil {
+		return err
+	}
+	_, err = f.f.Seek(off, io.SeekStart)
+	return err
+}
+
+// zeroExtend writes zeros to file from offset from up to offset to.
+func zeroExtend(file absfs.File, from, to int64) error {
+	if _, err := file.Seek(from, io.SeekStart); err != nil {
+		return err
+	}
+	zeros := make([]byte, min(to-from, 32*1024))
+	for n := to - from; n > 0; {
+		w, err := file.Write(zeros[:min(n, int64(len(zeros)))])
+		if err != nil {
+			return err
+		}
+		n -= int64(w)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package ptfs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// shrinkOnlyFS ignores Truncate calls growing a file.
+type shrinkOnlyFS struct {
+	absfs.FileSystem
+}
+
+func (fs shrinkOnlyFS) Truncate(name string, size int64) error {
+	info, err := fs.Stat(name)
+	if err != nil || size > info.Size() {
+		return err
+	}
+	return fs.FileSystem.Truncate(name, size)
+}
+
+func (fs shrinkOnlyFS) Create(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return shrinkOnlyFile{f}, nil
+}
+
+type shrinkOnlyFile struct {
+	absfs.File
+}
+
+func (f shrinkOnlyFile) Truncate(size int64) error {
+	info, err := f.Stat()
+	if err != nil || size > info.Size() {
+		return err
+	}
+	return f.File.Truncate(size)
+}
+
+func TestTruncateGrow(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(shrinkOnlyFS{mfs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "hello" + strings.Repeat("\x00", 5)
+
+	writeFile(t, fs, "/a", "hello")
+	if err := fs.Truncate("/a", 10); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/a"); got != want {
+		t.Fatalf("after Truncate(10) = %q, want %q", got, want)
+	}
+	if err := fs.Truncate("/a", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/a"); got != "he" {
+		t.Fatalf("after Truncate(2) = %q, want %q", got, "he")
+	}
+
+	f, err := fs.Create("/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(10); err != nil {
+		t.Fatal(err)
+	}
+	// The offset is unchanged by Truncate.
+	if _, err := f.WriteString("!"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if got, want := readAll(t, fs, "/b"), "hello!"+strings.Repeat("\x00", 4); got != want {
+		t.Fatalf("after File.Truncate(10) = %q, want %q", got, want)
+	}
+}
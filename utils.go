@@ -36,3 +36,19 @@ func UnwrapSymlinkFS(fs absfs.SymlinkFileSystem) absfs.SymlinkFileSystem {
 	}
 	return fs
 }
+
+// DeepUnwrapFS repeatedly unwraps pass through filesystems, including
+// pass through symlink filesystems, and returns the first `absfs.FileSystem`
+// that is not a pass through filesystem.
+func DeepUnwrapFS(fs absfs.FileSystem) absfs.FileSystem {
+	for {
+		switch pfs := fs.(type) {
+		case *FileSystem:
+			fs = pfs.fs
+		case *SymlinkFileSystem:
+			fs = pfs.sfs
+		default:
+			return fs
+		}
+	}
+}
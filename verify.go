@@ -0,0 +1,93 @@
+package ptfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/absfs/absfs"
+)
+
+// ErrVerifyFailed is returned, wrapped in a *os.PathError, by Close when a
+// file written with WithVerifyWrites does not read back as written.
+var ErrVerifyFailed = errors.New("written content does not read back")
+
+// WithVerifyWrites makes Close of files opened for writing with O_TRUNC, or
+// by Create, read the file back from the underlying filesystem and compare
+// it with a hash of what was written, returning an error wrapping
+// ErrVerifyFailed if they differ. This catches silent corruption by
+// unreliable filesystems at roughly twice the cost of writing. Only files
+// written sequentially can be verified, so files on which WriteAt, Seek or
+// Truncate is called are not verified.
+func WithVerifyWrites() Option {
+	return func(f *FileSystem) error {
+		f.verifyWrites = true
+		return nil
+	}
+}
+
+// verifyFile hashes the bytes written to a file.
+type verifyFile struct {
+	absfs.File
+	h            hash.Hash
+	unverifiable bool
+}
+
+func newVerifyFile(f absfs.File) *verifyFile {
+	return &verifyFile{File: f, h: sha256.New()}
+}
+
+func (f *verifyFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.h.Write(p[:n])
+	return n, err
+}
+
+func (f *verifyFile) WriteString(s string) (int, error) {
+	n, err := f.File.WriteString(s)
+	io.WriteString(f.h, s[:n])
+	return n, err
+}
+
+func (f *verifyFile) WriteAt(b []byte, off int64) (int, error) {
+	f.unverifiable = true
+	return f.File.WriteAt(b, off)
+}
+
+func (f *verifyFile) Seek(offset int64, whence int) (int64, error) {
+	f.unverifiable = true
+	return f.File.Seek(offset, whence)
+}
+
+func (f *verifyFile) Truncate(size int64) error {
+	f.unverifiable = true
+	return f.File.Truncate(size)
+}
+
+// verify reads back the named file, written through vf, from the underlying
+// filesystem and checks it matches what was written.
+func (f *FileSystem) verify(name string, vf *verifyFile) error {
+	if vf.unverifiable {
+		return nil
+	}
+	p, err := f.mapPath("Open", name)
+	if err != nil {
+		return err
+	}
+	file, err := f.fs.Open(p)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), vf.h.Sum(nil)) {
+		return &os.PathError{Op: "verify", Path: name, Err: ErrVerifyFailed}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package ptfs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+// corruptFS opens files whose reads flip the bits of the first byte read.
+type corruptFS struct {
+	absfs.FileSystem
+}
+
+func (fs corruptFS) Open(name string) (absfs.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &corruptFile{File: f}, nil
+}
+
+type corruptFile struct {
+	absfs.File
+	done bool
+}
+
+func (f *corruptFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	if n > 0 && !f.done {
+		p[0] ^= 0xff
+		f.done = true
+	}
+	return n, err
+}
+
+func TestWithVerifyWrites(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithVerifyWrites())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/good", "data")
+
+	bad, err := ptfs.NewFS(corruptFS{mfs}, ptfs.WithVerifyWrites())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := bad.Create("/bad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); !errors.Is(err, ptfs.ErrVerifyFailed) {
+		t.Fatalf("Close = %v, want %v", err, ptfs.ErrVerifyFailed)
+	}
+}
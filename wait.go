@@ -0,0 +1,39 @@
+package ptfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// Bounds for the poll interval of WaitForFile.
+const (
+	minPollInterval = time.Millisecond
+	maxPollInterval = time.Minute
+)
+
+// WaitForFile polls Stat every poll interval until name exists, returning nil
+// once it does or ctx.Err() if ctx is done first. It returns immediately if
+// name already exists. poll is clamped to between 1ms and 1 minute. Errors
+// from Stat other than one reporting that name does not exist are returned
+// without waiting further.
+func (f *FileSystem) WaitForFile(ctx context.Context, name string, poll time.Duration) error {
+	poll = min(max(poll, minPollInterval), maxPollInterval)
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		_, err := f.Stat(name)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ptfs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWaitForFile(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := make(chan error, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		f, err := fs.Create("/ready")
+		if err == nil {
+			err = f.Close()
+		}
+		created <- err
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := fs.WaitForFile(ctx, "/ready", 5*time.Millisecond); err != nil {
+		t.Fatalf("WaitForFile = %v, want nil", err)
+	}
+	if err := <-created; err != nil {
+		t.Fatal(err)
+	}
+
+	// An existing file returns at once, even with a long poll interval.
+	start := time.Now()
+	if err := fs.WaitForFile(ctx, "/ready", time.Hour); err != nil {
+		t.Fatalf("WaitForFile on existing file = %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("WaitForFile on existing file took %v", d)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := fs.WaitForFile(short, "/never", 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForFile = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForFileCategorized(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithCategorizedErrors())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A categorized not exist error still means the file is not there yet.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		if f, err := fs.Create("/ready"); err == nil {
+			f.Close()
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := fs.WaitForFile(ctx, "/ready", 5*time.Millisecond); err != nil {
+		t.Fatalf("WaitForFile = %v, want nil", err)
+	}
+}
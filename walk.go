@@ -0,0 +1,175 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// lstat returns a FileInfo describing the named file without following a
+// final symbolic link, when the underlying filesystem supports symbolic
+// links, otherwise it returns Stat.
+func (f *FileSystem) lstat(name string) (info os.FileInfo, err error) {
+	l := f.caps.lstater
+	if l == nil {
+		return f.Stat(name)
+	}
+	defer f.done("Lstat", time.Now(), &err)
+	if name, err = f.path("Lstat", name); err != nil {
+		return nil, err
+	}
+	err = f.retry(func() (err error) {
+		info, err = l.Lstat(name)
+		return err
+	})
+	return f.inLocation(f.canonical(info)), err
+}
+
+// readDir returns the entries of the named directory sorted by name,
+// excluding "." and "..".
+func (f *FileSystem) readDir(name string) ([]os.FileInfo, error) {
+	dir, err := f.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := dir.Readdir(-1)
+	dir.Close()
+	if err != nil {
+		return nil, err
+	}
+	list := infos[:0]
+	for _, info := range infos {
+		if info.Name() != "." && info.Name() != ".." {
+			list = append(list, info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list, nil
+}
+
+// Walk walks the file tree rooted at root, calling fn for each file or
+// directory in the tree, including root, as filepath.Walk does. Files are
+// visited in lexical order, and symbolic links are not followed. If the tree
+// is deeper than allowed by WithMaxDepth, Walk returns an error wrapping
+// ErrMaxDepthExceeded.
+func (f *FileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	info, err := f.lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = f.walk(root, info, 0, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (f *FileSystem) walk(name string, info os.FileInfo, depth int, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(name, info, nil)
+	}
+	infos, err := f.readDir(name)
+	err1 := fn(name, info, err)
+	if err != nil || err1 != nil {
+		return err1
+	}
+	if len(infos) > 0 && f.maxDepth > 0 && depth >= f.maxDepth {
+		return f.depthExceeded("walk", path.Join(name, infos[0].Name()))
+	}
+	for _, info := range infos {
+		err := f.walk(path.Join(name, info.Name()), info, depth+1, fn)
+		if err != nil && (!info.IsDir() || err != filepath.SkipDir) {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkDirs walks the directories of the tree rooted at root, calling fn for
+// root and each directory below it in lexical order, without calling it for
+// files. If fn returns filepath.SkipDir the directory's subdirectories are
+// skipped, and filepath.SkipAll skips everything remaining. Any other error
+// from fn, or from reading a directory, stops the walk and is returned.
+// Symbolic links to directories are not followed. If root is not a
+// directory, WalkDirs returns a *os.PathError wrapping syscall.ENOTDIR.
+func (f *FileSystem) WalkDirs(root string, fn func(path string, info os.FileInfo) error) error {
+	info, err := f.lstat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "walkdirs", Path: root, Err: syscall.ENOTDIR}
+	}
+	err = f.walkDirs(root, info, 0, fn)
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (f *FileSystem) walkDirs(name string, info os.FileInfo, depth int, fn func(string, os.FileInfo) error) error {
+	if err := fn(name, info); err != nil {
+		return err
+	}
+	infos, err := f.readDir(name)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		if f.maxDepth > 0 && depth >= f.maxDepth {
+			return f.depthExceeded("walk", path.Join(name, info.Name()))
+		}
+		err := f.walkDirs(path.Join(name, info.Name()), info, depth+1, fn)
+		if err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkList returns the paths of every file and directory below root,
+// relative to root and sorted.
+func (f *FileSystem) WalkList(root string) ([]string, error) {
+	return f.WalkListFunc(root, nil)
+}
+
+// WalkListFunc returns the paths below root for which include returns true,
+// relative to root and sorted. A nil include includes every path.
+func (f *FileSystem) WalkListFunc(root string, include func(name string, info os.FileInfo) bool) ([]string, error) {
+	var list []string
+	err := f.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == root {
+			return nil
+		}
+		rel := relPath(root, name)
+		if include == nil || include(rel, info) {
+			list = append(list, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// relPath returns name, which is below root, relative to root.
+func relPath(root, name string) string {
+	root = path.Clean(root)
+	if root == "/" {
+		return strings.TrimPrefix(name, "/")
+	}
+	return strings.TrimPrefix(name, root+"/")
+}
@@ -0,0 +1,95 @@
+package ptfs_test
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWalkList(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/root/b/c", "/root/a"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"/root/z.txt", "/root/a/x.go", "/root/b/c/y.txt"} {
+		writeFile(t, fs, name, name)
+	}
+
+	list, err := fs.WalkList("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "a/x.go", "b", "b/c", "b/c/y.txt", "z.txt"}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("WalkList = %q, want %q", list, want)
+	}
+
+	list, err = fs.WalkListFunc("/root", func(name string, info os.FileInfo) bool {
+		return path.Ext(name) == ".txt"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"b/c/y.txt", "z.txt"}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("WalkListFunc = %q, want %q", list, want)
+	}
+}
+
+func TestWalkDirs(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"/root/b/deep", "/root/a", "/root/c/skip/deeper"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"/root/file", "/root/a/file", "/root/b/deep/file"} {
+		writeFile(t, fs, file, "x")
+	}
+
+	var dirs []string
+	err = fs.WalkDirs("/root", func(name string, info os.FileInfo) error {
+		if !info.IsDir() {
+			t.Errorf("%s is not a directory", name)
+		}
+		dirs = append(dirs, name)
+		if name == "/root/c/skip" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/root", "/root/a", "/root/b", "/root/b/deep", "/root/c", "/root/c/skip"}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("WalkDirs visited %v, want %v", dirs, want)
+	}
+
+	if err := fs.WalkDirs("/root/file", func(string, os.FileInfo) error { return nil }); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("WalkDirs of a file = %v, want ENOTDIR", err)
+	}
+}
@@ -0,0 +1,23 @@
+package ptfs
+
+import (
+	"os"
+
+	"github.com/absfs/absfs"
+)
+
+// WithFile opens the named file as OpenFile does, calls fn with it, and
+// closes it when fn returns, even if fn panics. It returns the error from fn
+// if there is one, and otherwise the error from Close.
+func (f *FileSystem) WithFile(name string, flag int, perm os.FileMode, fn func(absfs.File) error) (err error) {
+	file, err := f.OpenFile(name, flag, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return fn(file)
+}
@@ -0,0 +1,57 @@
+package ptfs_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWithFile(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fs.WithFile("/file", os.O_WRONLY|os.O_CREATE, 0644, func(f absfs.File) error {
+		_, err := f.WriteString("data")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fs.OpenHandles()); n != 0 {
+		t.Fatalf("%d handles open after WithFile", n)
+	}
+
+	errFn := errors.New("fn failed")
+	err = fs.WithFile("/file", os.O_RDONLY, 0, func(f absfs.File) error {
+		io.ReadAll(f)
+		return errFn
+	})
+	if err != errFn {
+		t.Fatalf("WithFile = %v, want %v", err, errFn)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic in fn was not propagated")
+			}
+		}()
+		fs.WithFile("/file", os.O_RDONLY, 0, func(absfs.File) error {
+			panic("boom")
+		})
+	}()
+	if n := len(fs.OpenHandles()); n != 0 {
+		t.Fatalf("%d handles open after a panic in fn", n)
+	}
+}
@@ -0,0 +1,64 @@
+package ptfs
+
+import "github.com/absfs/absfs"
+
+// WrapFile returns a File that passes everything through to f, except that
+// the bytes returned by Read and ReadAt are first passed through onRead, and
+// the bytes given to Write, WriteAt and WriteString through onWrite, either
+// of which may be nil to leave that direction unchanged. The interceptors
+// may modify and return the slice they are given.
+//
+// Offsets and byte counts are those of f. If onRead returns more bytes than
+// it was given, the excess is dropped, and if onWrite changes the length of
+// the data, Write reports len(p) bytes written on success regardless, so
+// interceptors that change lengths break offset accounting for Seek, ReadAt
+// and WriteAt.
+func WrapFile(f absfs.File, onRead, onWrite func(p []byte) []byte) absfs.File {
+	return &interceptedFile{File: f, onRead: onRead, onWrite: onWrite}
+}
+
+type interceptedFile struct {
+	absfs.File
+	onRead, onWrite func(p []byte) []byte
+}
+
+func (f *interceptedFile) read(p []byte, n int, err error) (int, error) {
+	if f.onRead != nil && n > 0 {
+		n = copy(p, f.onRead(p[:n]))
+	}
+	return n, err
+}
+
+func (f *interceptedFile) write(p []byte, write func([]byte) (int, error)) (int, error) {
+	if f.onWrite == nil {
+		return write(p)
+	}
+	b := f.onWrite(append([]byte(nil), p...))
+	n, err := write(b)
+	if err != nil {
+		return min(n, len(p)), err
+	}
+	return len(p), nil
+}
+
+func (f *interceptedFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	return f.read(p, n, err)
+}
+
+func (f *interceptedFile) ReadAt(b []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(b, off)
+	return f.read(b, n, err)
+}
+
+func (f *interceptedFile) Write(p []byte) (int, error) {
+	return f.write(p, f.File.Write)
+}
+
+func (f *interceptedFile) WriteAt(b []byte, off int64) (int, error) {
+	return f.write(b, func(b []byte) (int, error) { return f.File.WriteAt(b, off) })
+}
+
+func (f *interceptedFile) WriteString(s string) (int, error) {
+	return f.write([]byte(s), f.File.Write)
+}
@@ -0,0 +1,47 @@
+package ptfs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWrapFile(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/data", "hello, world")
+
+	f, err := mfs.Open("/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wf := ptfs.WrapFile(f, bytes.ToUpper, nil)
+	data, err := io.ReadAll(wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "HELLO, WORLD" {
+		t.Fatalf("Read = %q, want %q", data, "HELLO, WORLD")
+	}
+	wf.Close()
+
+	f, err = mfs.Create("/redacted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wf = ptfs.WrapFile(f, nil, func(p []byte) []byte {
+		return bytes.ReplaceAll(p, []byte("secret"), []byte("XXXXXX"))
+	})
+	if n, err := wf.WriteString("the secret word"); err != nil || n != 15 {
+		t.Fatalf("WriteString = %d, %v, want 15, nil", n, err)
+	}
+	wf.Close()
+	if got := readAll(t, mfs, "/redacted"); got != "the XXXXXX word" {
+		t.Fatalf("written %q, want %q", got, "the XXXXXX word")
+	}
+}
@@ -0,0 +1,257 @@
+package ptfs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sort"
+)
+
+// Overwrite replaces the content of the named file with data. An existing
+// file keeps its permissions, a new file is created with permissions 0644.
+func (f *FileSystem) Overwrite(name string, data []byte) error {
+	perm := os.FileMode(0644)
+	info, err := f.Stat(name)
+	switch {
+	case err == nil:
+		perm = info.Mode().Perm()
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	return f.writeFile(name, data, perm)
+}
+
+// writeFile writes data to the named file, creating it with permissions perm
+// if necessary, and truncating it otherwise.
+func (f *FileSystem) writeFile(name string, data []byte, perm os.FileMode) error {
+	file, err := f.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WriteFileIfChanged writes data to the named file, creating it with
+// permissions perm if necessary, unless the file already holds exactly data.
+// It reports whether the file was written. Leaving unchanged files alone
+// avoids needlessly updating their modification times. The existing content
+// is compared in chunks rather than read into memory at once.
+func (f *FileSystem) WriteFileIfChanged(name string, data []byte, perm os.FileMode) (changed bool, err error) {
+	same, err := f.hasContent(name, data)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if same {
+		return false, nil
+	}
+	if err := f.writeFile(name, data, perm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// hasContent reports whether the named file holds exactly data.
+func (f *FileSystem) hasContent(name string, data []byte) (bool, error) {
+	file, err := f.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != int64(len(data)) {
+		return false, nil
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := io.ReadFull(file, buf)
+		if n > len(data) || !bytes.Equal(buf[:n], data[:n]) {
+			return false, nil
+		}
+		data = data[n:]
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return len(data) == 0, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// BufferedWriter creates or truncates the named file with permissions perm,
+// and returns a buffered writer to it along with a function that flushes the
+// buffer and closes the file, returning the first error encountered. Callers
+// must call the returned function, or buffered data is lost and the file is
+// left open.
+func (f *FileSystem) BufferedWriter(name string, perm os.FileMode) (*bufio.Writer, func() error, error) {
+	file, err := f.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := bufio.NewWriter(file)
+	return w, func() error {
+		err := w.Flush()
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// WriteSeeker creates or truncates the named file with permissions perm,
+// and returns an io.WriteSeeker over it, for encoders that seek back to
+// patch headers once the data is written, along with a function that closes
+// the file. If files of the underlying filesystem do not support Seek, the
+// content is instead kept in memory, costing as much memory as the file
+// being written, and written to the file by the close function. Callers must
+// call the returned function, or the file is left open and, in that case,
+// empty.
+func (f *FileSystem) WriteSeeker(name string, perm os.FileMode) (io.WriteSeeker, func() error, error) {
+	file, err := f.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.(*File).SupportsSeek() {
+		return file, file.Close, nil
+	}
+	w := &memWriteSeeker{}
+	return w, func() error {
+		_, err := file.Write(w.buf)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// memWriteSeeker is an in-memory io.WriteSeeker. Writing past the end
+// extends the buffer, filling any gap with zeros.
+type memWriteSeeker struct {
+	buf []byte
+	off int64
+}
+
+func (w *memWriteSeeker) Write(p []byte) (int, error) {
+	if end := w.off + int64(len(p)); end > int64(len(w.buf)) {
+		w.buf = append(w.buf, make([]byte, end-int64(len(w.buf)))...)
+	}
+	n := copy(w.buf[w.off:], p)
+	w.off += int64(n)
+	return n, nil
+}
+
+func (w *memWriteSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += w.off
+	case io.SeekEnd:
+		offset += int64(len(w.buf))
+	default:
+		return 0, errors.New("ptfs: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("ptfs: negative position")
+	}
+	w.off = offset
+	return offset, nil
+}
+
+// WriteBatch writes each file in files, keyed by name, with permissions perm,
+// creating parent directories as needed. Every file is first written to a
+// temporary file beside it, and only once all of them have been written are
+// they renamed into place, so a failure writing any file leaves every
+// original untouched. The renames are not a single atomic step: if one of
+// them fails, the files renamed before it have already been replaced, and
+// the remaining temporary files are removed.
+func (f *FileSystem) WriteBatch(files map[string][]byte, perm os.FileMode) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	temps := make([]string, 0, len(names))
+	removeTemps := func() {
+		for _, tmp := range temps {
+			f.Remove(tmp)
+		}
+	}
+	for _, name := range names {
+		if err := f.MkdirAll(path.Dir(name), 0755); err != nil {
+			removeTemps()
+			return err
+		}
+		tmp, err := f.writeTemp(name, bytes.NewReader(files[name]), perm)
+		if err != nil {
+			removeTemps()
+			return err
+		}
+		temps = append(temps, tmp)
+	}
+	for i, name := range names {
+		if err := f.Rename(temps[i], name); err != nil {
+			temps = temps[i:]
+			removeTemps()
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendLine appends line and a newline to the named file, creating it with
+// permissions 0644 if necessary. The line and newline are written with a
+// single Write to a file opened with O_APPEND, so concurrent calls do not
+// interleave partial lines as long as the underlying filesystem performs
+// each appending Write atomically, as memory filesystems and local files
+// generally do. With WithPerPathWriteLock, calls on the same path are
+// serialized regardless.
+func (f *FileSystem) AppendLine(name, line string) error {
+	file, err := f.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write([]byte(line + "\n"))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WithCreateParents makes CreateWith create any missing parent directories
+// of the file, with permissions perm, as MkdirAll does.
+func WithCreateParents(perm os.FileMode) Option {
+	return func(f *FileSystem) error {
+		f.parentPerm = perm
+		f.createParents = true
+		return nil
+	}
+}
+
+// CreateWith creates the named file, or truncates it if it exists, writes
+// content to it, closes it, and then sets its mode with Chmod, so the file
+// ends up with exactly mode, which may include bits that Create or a umask
+// would not set, and may deny writing. With WithCreateParents, missing
+// parent directories are created first.
+func (f *FileSystem) CreateWith(name string, content []byte, mode os.FileMode) error {
+	if f.createParents {
+		if err := f.MkdirAll(f.Dir(name), f.parentPerm); err != nil {
+			return err
+		}
+	}
+	if err := f.writeFile(name, content, 0600); err != nil {
+		return err
+	}
+	return f.Chmod(name, mode)
+}
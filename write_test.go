@@ -0,0 +1,307 @@
+package ptfs_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestOverwrite(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/secret", "a longer original content")
+	if err := fs.Chmod("/secret", 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Overwrite("/secret", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/secret"); got != "new" {
+		t.Errorf("content = %q, want %q", got, "new")
+	}
+	assertMode(t, fs, "/secret", 0600)
+
+	if err := fs.Overwrite("/fresh", []byte("fresh")); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, fs, "/fresh", 0644)
+}
+
+func TestBufferedWriter(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, closeFn, err := fs.BufferedWriter("/out", 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+		fmt.Fprintf(&want, "line %d\n", i)
+	}
+	if err := closeFn(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/out"); got != want.String() {
+		t.Errorf("content has %d bytes, want %d", len(got), want.Len())
+	}
+}
+
+// noSeekFS returns files that do not support Seek.
+type noSeekFS struct {
+	absfs.FileSystem
+}
+
+func (fs noSeekFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	f, err := fs.FileSystem.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return noSeekFile{f}, nil
+}
+
+type noSeekFile struct {
+	absfs.File
+}
+
+func (f noSeekFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, &os.PathError{Op: "seek", Path: f.Name(), Err: ptfs.ErrNotSupported}
+}
+
+func TestWriteSeeker(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		fs   absfs.FileSystem
+	}{
+		{"memfs", mfs},
+		{"noseek", noSeekFS{mfs}},
+	} {
+		fs, err := ptfs.NewFS(test.fs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "/" + test.name
+		writeFile(t, fs, name, "previous content, longer than the new one")
+
+		w, closeFn, err := fs.WriteSeeker(name, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write a placeholder header, the data, then patch the header with
+		// the data length.
+		if _, err := w.Write([]byte("LEN=??;")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("payload")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Seek(4, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte("07")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Seek(0, io.SeekEnd); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(".")); err != nil {
+			t.Fatal(err)
+		}
+		if err := closeFn(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := readAll(t, fs, name), "LEN=07;payload."; got != want {
+			t.Errorf("%s: content = %q, want %q", test.name, got, want)
+		}
+	}
+}
+
+func TestWriteFileIfChanged(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := fs.WriteFileIfChanged("/config", []byte("a=1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("creating the file reported changed = false")
+	}
+	past := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := fs.Chtimes("/config", past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err = fs.WriteFileIfChanged("/config", []byte("a=1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("writing identical content reported changed = true")
+	}
+	assertModTime(t, fs, "/config", past)
+
+	changed, err = fs.WriteFileIfChanged("/config", []byte("a=2"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("writing new content reported changed = false")
+	}
+	if got := readAll(t, fs, "/config"); got != "a=2" {
+		t.Errorf("content = %q, want %q", got, "a=2")
+	}
+}
+
+func TestWriteBatch(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/a", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/a/cfg", "old")
+	writeFile(t, fs, "/z", "not a directory")
+
+	err = fs.WriteBatch(map[string][]byte{
+		"/a/cfg": []byte("new"),
+		"/z/cfg": []byte("new"),
+	}, 0644)
+	if err == nil {
+		t.Fatal("WriteBatch below a regular file succeeded")
+	}
+	if got := readAll(t, fs, "/a/cfg"); got != "old" {
+		t.Fatalf("/a/cfg = %q after a failed batch, want %q", got, "old")
+	}
+	if names, _ := readDirNames(fs, "/a"); len(names) != 1 {
+		t.Fatalf("failed batch left temporary files: %v", names)
+	}
+
+	err = fs.WriteBatch(map[string][]byte{
+		"/a/cfg":     []byte("new"),
+		"/b/c/other": []byte("other"),
+	}, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/a/cfg"); got != "new" {
+		t.Fatalf("/a/cfg = %q, want %q", got, "new")
+	}
+	if got := readAll(t, fs, "/b/c/other"); got != "other" {
+		t.Fatalf("/b/c/other = %q, want %q", got, "other")
+	}
+	assertMode(t, fs, "/b/c/other", 0600)
+}
+
+func TestAppendLine(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithPerPathWriteLock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := fs.AppendLine("/log", fmt.Sprintf("line %02d %s", i, strings.Repeat("x", 100))); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(readAll(t, fs, "/log"), "\n")
+	if last := lines[len(lines)-1]; last != "" {
+		t.Fatalf("log does not end with a newline: %q", last)
+	}
+	lines = lines[:len(lines)-1]
+	if len(lines) != n {
+		t.Fatalf("got %d lines, want %d", len(lines), n)
+	}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		var i int
+		if _, err := fmt.Sscanf(line, "line %02d", &i); err != nil || len(line) != 108 {
+			t.Fatalf("malformed line %q", line)
+		}
+		seen[line] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("got %d distinct lines, want %d", len(seen), n)
+	}
+}
+
+func TestCreateWith(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateWith("/fixture", []byte("content"), 0400); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, fs, "/fixture"); got != "content" {
+		t.Errorf("content = %q, want %q", got, "content")
+	}
+	assertMode(t, fs, "/fixture", 0400)
+
+	if err := fs.CreateWith("/a/b/file", nil, 0644); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("CreateWith without parents = %v, want ErrNotExist", err)
+	}
+	fs, err = ptfs.NewFS(mfs, ptfs.WithCreateParents(0750))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateWith("/a/b/script", []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, fs, "/a/b/script", 0755)
+	assertMode(t, fs, "/a/b", 0750)
+}
@@ -0,0 +1,75 @@
+package ptfs
+
+import (
+	"os"
+	"path"
+
+	"github.com/absfs/absfs"
+)
+
+// WithPerPathWriteLock serializes write operations on each path. OpenFile and
+// Create with a writable flag, Truncate, Chmod, Chtimes, Remove, RemoveAll and
+// Rename acquire an exclusive lock on the paths they modify, so writes to the
+// same path never interleave while writes to different paths proceed in
+// parallel. Files opened for writing hold their lock until they are closed.
+// Rename locks both of its paths in a consistent order.
+//
+// Because the lock is held by open files, a goroutine holding a file open for
+// writing must close it before modifying the same path again through the
+// FileSystem, otherwise it deadlocks.
+func WithPerPathWriteLock() Option {
+	return func(f *FileSystem) error {
+		f.writeLock = true
+		return nil
+	}
+}
+
+// lockWrite acquires the write lock for name and returns a function releasing
+// it. It does nothing unless WithPerPathWriteLock was given.
+func (f *FileSystem) lockWrite(name string) func() {
+	if !f.writeLock {
+		return func() {}
+	}
+	return f.writeLocks.lock(name, true)
+}
+
+// lockWrite2 acquires the write locks for both a and b, in sorted order so
+// that concurrent callers locking the same pair cannot deadlock.
+func (f *FileSystem) lockWrite2(a, b string) func() {
+	if !f.writeLock {
+		return func() {}
+	}
+	a, b = path.Clean(a), path.Clean(b)
+	if a == b {
+		return f.writeLocks.lock(a, true)
+	}
+	if b < a {
+		a, b = b, a
+	}
+	unlockA := f.writeLocks.lock(a, true)
+	unlockB := f.writeLocks.lock(b, true)
+	return func() {
+		unlockB()
+		unlockA()
+	}
+}
+
+// lockOpen acquires the write lock for a file about to be opened with flag,
+// if the flag is writable.
+func (f *FileSystem) lockOpen(name string, flag int) func() {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_TRUNC|os.O_APPEND) == 0 {
+		return func() {}
+	}
+	return f.lockWrite(name)
+}
+
+// unlockOnClose releases unlock when file is closed, before any other close
+// hooks run so that they may themselves take the lock.
+func unlockOnClose(file absfs.File, unlock func()) absfs.File {
+	pf := file.(*File)
+	pf.onClose = append([]func() error{func() error {
+		unlock()
+		return nil
+	}}, pf.onClose...)
+	return file
+}
@@ -0,0 +1,127 @@
+package ptfs_test
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestWithPerPathWriteLock(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithPerPathWriteLock())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each writer appends its letter in several small writes; with the lock
+	// held for the life of the handle the runs never interleave.
+	var wg sync.WaitGroup
+	for _, c := range "abcd" {
+		wg.Add(1)
+		go func(c string) {
+			defer wg.Done()
+			f, err := fs.OpenFile("/log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer f.Close()
+			for i := 0; i < 8; i++ {
+				if _, err := f.Write([]byte(c)); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}(string(c))
+	}
+	wg.Wait()
+	got := readAll(t, fs, "/log")
+	if len(got) != 32 {
+		t.Fatalf("got %d bytes, want 32", len(got))
+	}
+	for i := 0; i < len(got); i += 8 {
+		if run := got[i : i+8]; run != strings.Repeat(run[:1], 8) {
+			t.Fatalf("writes interleaved: %q", got)
+		}
+	}
+
+	// Handles on different paths do not wait for each other.
+	a, err := fs.Create("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		b, err := fs.Create("/b")
+		if err == nil {
+			err = b.Close()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("write to a different path was blocked")
+	}
+
+	// Operations on a locked path wait until the handle is closed.
+	go func() { done <- fs.Truncate("/a", 0) }()
+	select {
+	case <-done:
+		t.Fatal("Truncate did not wait for the open handle")
+	case <-time.After(20 * time.Millisecond):
+	}
+	a.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithPerPathWriteLockRename(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithPerPathWriteLock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, fs, "/x", "x")
+	writeFile(t, fs, "/y", "y")
+
+	// Renames in opposite directions must not deadlock.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			fs.Rename("/x", "/y")
+		}()
+		go func() {
+			defer wg.Done()
+			fs.Rename("/y", "/x")
+		}()
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("concurrent renames deadlocked")
+	}
+}
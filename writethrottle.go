@@ -0,0 +1,110 @@
+package ptfs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewWriteThrottleFS returns a FileSystem limiting writes to fs to
+// writesPerSec, as set by WithWriteRateLimit, while reads run at full
+// speed.
+func NewWriteThrottleFS(fs absfs.FileSystem, writesPerSec float64, burst int, opts ...Option) (*FileSystem, error) {
+	return NewFS(fs, append([]Option{WithWriteRateLimit(writesPerSec, burst)}, opts...)...)
+}
+
+// WithWriteRateLimit limits the rate of write operations with a token
+// bucket refilled at writesPerSec and holding up to burst tokens, which
+// starts full. Every Write, WriteAt and WriteString on a file opened for
+// writing, and every Create, Mkdir, MkdirAll, Remove, RemoveAll, Rename,
+// Truncate, Chmod, Chtimes and Chown, OpenFile with O_CREATE or O_TRUNC, and
+// Symlink and Lchown through a SymlinkFileSystem, takes a token, waiting
+// until one is available. Operations take
+// their token once their path is permitted, so an operation refused by
+// Freeze, the allowlist or another check neither waits nor uses a token.
+// Other operations are not limited. A writesPerSec of 0 or less disables the
+// limit, and a burst of less than 1 is treated as 1.
+//
+// The context aware methods, such as CreateContext and ChownContext, take a
+// single token, waiting for it until their context is done, and then fail
+// with the context's error. Symlink and Lchown have no context aware
+// variants, and cannot be abandoned while they wait.
+func WithWriteRateLimit(writesPerSec float64, burst int) Option {
+	return func(f *FileSystem) error {
+		f.writeTokens = nil
+		if writesPerSec > 0 {
+			f.writeTokens = newTokenBucket(writesPerSec, max(burst, 1))
+		}
+		return nil
+	}
+}
+
+// tokenBucket is a token bucket rate limiter. A nil *tokenBucket imposes no
+// limit.
+type tokenBucket struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait takes a token, waiting until one is available.
+func (b *tokenBucket) wait() {
+	b.take(context.Background())
+}
+
+// take takes a token, waiting until one is available or until ctx is done.
+func (b *tokenBucket) take(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type writeThrottledFile struct {
+	absfs.File
+	tokens *tokenBucket
+}
+
+func (f *writeThrottledFile) Write(p []byte) (int, error) {
+	f.tokens.wait()
+	return f.File.Write(p)
+}
+
+func (f *writeThrottledFile) WriteAt(b []byte, off int64) (int, error) {
+	f.tokens.wait()
+	return f.File.WriteAt(b, off)
+}
+
+func (f *writeThrottledFile) WriteString(s string) (int, error) {
+	f.tokens.wait()
+	return f.File.WriteString(s)
+}
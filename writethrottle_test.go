@@ -0,0 +1,155 @@
+package ptfs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/absfs/memfs"
+	"github.com/absfs/ptfs"
+)
+
+func TestNewWriteThrottleFS(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/data", "data")
+	fs, err := ptfs.NewWriteThrottleFS(mfs, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 500; i++ {
+		if _, err := fs.Stat("/data"); err != nil {
+			t.Fatal(err)
+		}
+		if got := readAll(t, fs, "/data"); got != "data" {
+			t.Fatalf("read %q", got)
+		}
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("reads took %v, want them unthrottled", d)
+	}
+
+	// The first write uses the burst; the next ten wait 10ms each, whether
+	// they are operations or writes to files.
+	start = time.Now()
+	for i := 0; i < 6; i++ {
+		if err := fs.Mkdir(fmt.Sprintf("/dir%d", i), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := fs.OpenFile("/data", os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("11 writes took %v, want at least 100ms", d)
+	}
+}
+
+func TestWriteRateLimitContext(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs, ptfs.WithWriteRateLimit(0.1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("/a", 0755); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := fs.MkdirContext(ctx, "/b", 0755); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("MkdirContext = %v, want DeadlineExceeded", err)
+	}
+	if _, err := fs.Stat("/b"); err == nil {
+		t.Error("/b was created")
+	}
+}
+
+func TestWriteRateLimitDenied(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ptfs.NewFS(mfs,
+		ptfs.WithWriteRateLimit(0.1, 2),
+		ptfs.WithAllowlist(ptfs.Allowlist{"*": {"/ok"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Refused operations neither wait nor use up the burst.
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := fs.Mkdir("/denied", 0755); !errors.Is(err, os.ErrPermission) {
+			t.Fatalf("Mkdir(/denied) = %v, want ErrPermission", err)
+		}
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("refused operations took %v, want them unthrottled", d)
+	}
+
+	// Each context aware operation takes a single token.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := fs.MkdirContext(ctx, "/ok", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirContext(ctx, "/ok/a", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MkdirContext(ctx, "/ok/b", 0755); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("third MkdirContext = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestWriteRateLimitSymlinkChown(t *testing.T) {
+	mfs, err := memfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, mfs, "/data", "data")
+	fs, err := ptfs.NewSymlinkFS(mfs, ptfs.WithWriteRateLimit(100, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first symlink uses the burst; the next five wait 10ms each.
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := fs.Symlink("/data", fmt.Sprintf("/link%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Errorf("6 symlinks took %v, want at least 50ms", d)
+	}
+
+	slow, err := ptfs.NewFS(mfs, ptfs.WithWriteRateLimit(0.1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := slow.Chown("/data", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := slow.ChownContext(ctx, "/data", 0, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ChownContext = %v, want DeadlineExceeded", err)
+	}
+}